@@ -0,0 +1,223 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// dockerfile-checker verifies that every Dockerfile pins its base images by
+// digest, so that rebuilding a commit uses the same bases. Bases selected
+// through build args are resolved with the build args the goreleaser
+// configurations next to the Dockerfile pass to it, or with the ARG defaults
+// when no configuration builds it. Repositories listed with -allow-unpinned,
+// whose digests aren't pinned yet, are left out of the check.
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	dirFlag           = flag.String("dir", "distributions", "Directory to search for Dockerfiles")
+	allowUnpinnedFlag = flag.String("allow-unpinned", "", "Comma-separated image repositories whose bases aren't required to be pinned by digest, e.g. mcr.microsoft.com/windows/")
+)
+
+func main() {
+	flag.Parse()
+
+	var allowUnpinned []string
+	for _, repository := range strings.Split(*allowUnpinnedFlag, ",") {
+		if repository = strings.TrimSpace(repository); repository != "" {
+			allowUnpinned = append(allowUnpinned, repository)
+		}
+	}
+
+	var failed bool
+	err := filepath.WalkDir(*dirFlag, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isDockerfile(d.Name()) {
+			return nil
+		}
+		buildArgs, err := configBuildArgs(filepath.Dir(path), d.Name())
+		if err != nil {
+			return err
+		}
+		problems, err := checkDockerfile(path, buildArgs, allowUnpinned)
+		if err != nil {
+			return err
+		}
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, problem)
+			failed = true
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if failed {
+		log.Fatal("Check failed: base images must be pinned by digest, e.g. alpine:3.24@sha256:<digest>")
+	}
+}
+
+func isDockerfile(name string) bool {
+	return name == "Dockerfile" || strings.HasSuffix(name, ".dockerfile")
+}
+
+// goreleaserConfig holds the image builds of a goreleaser configuration.
+type goreleaserConfig struct {
+	Dockers []struct {
+		Dockerfile         string   `yaml:"dockerfile"`
+		BuildFlagTemplates []string `yaml:"build_flag_templates"`
+	} `yaml:"dockers"`
+	DockersV2 []struct {
+		Dockerfile string            `yaml:"dockerfile"`
+		BuildArgs  map[string]string `yaml:"build_args"`
+	} `yaml:"dockers_v2"`
+}
+
+// configBuildArgs returns the build args of every image built from a
+// Dockerfile by the goreleaser configurations in its directory.
+func configBuildArgs(dir, dockerfile string) ([]map[string]string, error) {
+	configs, err := filepath.Glob(filepath.Join(dir, ".goreleaser*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var buildArgs []map[string]string
+	for _, path := range configs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var cfg goreleaserConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, docker := range cfg.Dockers {
+			if cmp.Or(docker.Dockerfile, "Dockerfile") != dockerfile {
+				continue
+			}
+			args := map[string]string{}
+			for _, flag := range docker.BuildFlagTemplates {
+				if arg, ok := strings.CutPrefix(flag, "--build-arg="); ok {
+					key, value, _ := strings.Cut(arg, "=")
+					args[key] = value
+				}
+			}
+			buildArgs = append(buildArgs, args)
+		}
+		for _, docker := range cfg.DockersV2 {
+			if cmp.Or(docker.Dockerfile, "Dockerfile") == dockerfile {
+				buildArgs = append(buildArgs, docker.BuildArgs)
+			}
+		}
+	}
+	return buildArgs, nil
+}
+
+// checkDockerfile returns the base images of a Dockerfile not pinned by digest,
+// once built with each of the given build args. Without build args, the ARG
+// defaults are used. Bases from the allowUnpinned repositories may be unpinned.
+func checkDockerfile(path string, buildArgs []map[string]string, allowUnpinned []string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if len(buildArgs) == 0 {
+		buildArgs = []map[string]string{nil}
+	}
+
+	var problems []string
+	report := func(problem string) {
+		if !slices.Contains(problems, problem) {
+			problems = append(problems, problem)
+		}
+	}
+	// globalArgs are the ARGs declared before the first FROM, in order.
+	var globalArgs [][2]string
+	seenFrom := false
+	stages := map[string]bool{"scratch": true}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if strings.EqualFold(fields[0], "ARG") && !seenFrom {
+			key, value, _ := strings.Cut(fields[1], "=")
+			globalArgs = append(globalArgs, [2]string{key, strings.Trim(value, `"'`)})
+			continue
+		}
+		if !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		seenFrom = true
+		args := fields[1:]
+		for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		base := args[0]
+		if len(args) == 3 && strings.EqualFold(args[1], "AS") {
+			stages[strings.ToLower(args[2])] = true
+		}
+
+		for _, set := range buildArgs {
+			image, ok := expand(base, argValues(globalArgs, set))
+			name := base
+			if image != base && image != "" {
+				name = fmt.Sprintf("%s (%s)", base, image)
+			}
+			switch {
+			case !ok || strings.Contains(image, "{{"):
+				report(fmt.Sprintf("line %d: base %s can't be resolved from the build args", line, name))
+			case stages[strings.ToLower(image)], strings.Contains(image, "@sha256:"):
+			case slices.ContainsFunc(allowUnpinned, func(repository string) bool { return strings.HasPrefix(image, repository) }):
+			default:
+				report(fmt.Sprintf("line %d: base %s is not pinned by digest", line, name))
+			}
+		}
+	}
+	return problems, scanner.Err()
+}
+
+// argValues returns the values of the global ARGs: the build arg when given,
+// else the default, which may refer to the ARGs declared before.
+func argValues(globalArgs [][2]string, buildArgs map[string]string) map[string]string {
+	values := map[string]string{}
+	for _, arg := range globalArgs {
+		if value, ok := buildArgs[arg[0]]; ok {
+			values[arg[0]] = value
+			continue
+		}
+		values[arg[0]], _ = expand(arg[1], values)
+	}
+	return values
+}
+
+// expand replaces the $VAR and ${VAR} references of s, reporting whether all
+// of them have a value.
+func expand(s string, values map[string]string) (string, bool) {
+	ok := true
+	expanded := os.Expand(s, func(key string) string {
+		value, found := values[key]
+		if !found || value == "" {
+			ok = false
+		}
+		return value
+	})
+	return expanded, ok
+}
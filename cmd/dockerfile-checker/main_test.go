@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const pinnedAlpine = "alpine:3.24@sha256:28bd5fe8b56d1bd048e5babf5b10710ebe0bae67db86916198a6eec434943f8b"
+
+func TestCheckDockerfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		dockerfile    string
+		buildArgs     []map[string]string
+		allowUnpinned []string
+		want          []string
+	}{
+		{
+			name:       "pinned",
+			dockerfile: "FROM " + pinnedAlpine + " AS certs\nFROM scratch\nCOPY --from=certs /etc/ssl/certs /etc/ssl/certs\n",
+		},
+		{
+			name:       "unpinned",
+			dockerfile: "FROM --platform=linux/amd64 alpine:3.24\n",
+			want:       []string{"line 1: base alpine:3.24 is not pinned by digest"},
+		},
+		{
+			name:       "previous stage",
+			dockerfile: "FROM " + pinnedAlpine + " AS build\nFROM build\n",
+		},
+		{
+			name:       "build arg default",
+			dockerfile: "ARG VERSION=3.24\nFROM alpine:${VERSION}\n",
+			want:       []string{"line 2: base alpine:${VERSION} (alpine:3.24) is not pinned by digest"},
+		},
+		{
+			name:       "build arg from config pinned",
+			dockerfile: "ARG VERSION=2019\nARG IMAGE=mcr.microsoft.com/windows/nanoserver:ltsc${VERSION}\nFROM ${IMAGE}\n",
+			buildArgs: []map[string]string{
+				{"IMAGE": "mcr.microsoft.com/windows/nanoserver:ltsc2019@sha256:1234"},
+				{"IMAGE": "mcr.microsoft.com/windows/nanoserver:ltsc2022@sha256:5678"},
+			},
+		},
+		{
+			name:       "build arg from config unpinned",
+			dockerfile: "ARG VERSION=2019\nARG IMAGE=mcr.microsoft.com/windows/nanoserver:ltsc${VERSION}\nFROM ${IMAGE}\n",
+			buildArgs: []map[string]string{
+				{"IMAGE": "mcr.microsoft.com/windows/nanoserver:ltsc2019@sha256:1234"},
+				{"VERSION": "2022"},
+				{"VERSION": "2022"},
+			},
+			want: []string{"line 3: base ${IMAGE} (mcr.microsoft.com/windows/nanoserver:ltsc2022) is not pinned by digest"},
+		},
+		{
+			name:          "build arg from config allowed unpinned",
+			dockerfile:    "ARG IMAGE=mcr.microsoft.com/windows/nanoserver:ltsc2019\nFROM ${IMAGE}\nFROM alpine:3.24\n",
+			buildArgs:     []map[string]string{{"IMAGE": "mcr.microsoft.com/windows/servercore:ltsc2025"}, {}},
+			allowUnpinned: []string{"mcr.microsoft.com/windows/"},
+			want:          []string{"line 3: base alpine:3.24 is not pinned by digest"},
+		},
+		{
+			name:       "build arg unresolved",
+			dockerfile: "ARG IMAGE\nFROM ${IMAGE}\n",
+			buildArgs:  []map[string]string{{"IMAGE": "{{ .Env.IMAGE }}"}, {}},
+			want: []string{
+				"line 2: base ${IMAGE} ({{ .Env.IMAGE }}) can't be resolved from the build args",
+				"line 2: base ${IMAGE} can't be resolved from the build args",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Dockerfile")
+			if err := os.WriteFile(path, []byte(tt.dockerfile), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := checkDockerfile(path, tt.buildArgs, tt.allowUnpinned)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("checkDockerfile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigBuildArgs(t *testing.T) {
+	dir := t.TempDir()
+	config := `dockers:
+  - dockerfile: Windows.dockerfile
+    build_flag_templates:
+      - --pull
+      - --build-arg=WIN_VERSION=2022
+      - --build-arg=WIN_IMAGE=mcr.microsoft.com/windows/nanoserver:ltsc2022@sha256:1234
+  - build_flag_templates:
+      - --build-arg=SOURCE_DATE_EPOCH={{ .Env.SOURCE_DATE_EPOCH }}
+dockers_v2:
+  - dockerfile: Windows.dockerfile
+    build_args:
+      WIN_VERSION: "2025"
+`
+	if err := os.WriteFile(filepath.Join(dir, ".goreleaser.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := configBuildArgs(dir, "Windows.dockerfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d build arg sets, want 2: %v", len(got), got)
+	}
+	if got[0]["WIN_IMAGE"] != "mcr.microsoft.com/windows/nanoserver:ltsc2022@sha256:1234" || got[0]["WIN_VERSION"] != "2022" {
+		t.Errorf("unexpected dockers build args %v", got[0])
+	}
+	if got[1]["WIN_VERSION"] != "2025" {
+		t.Errorf("unexpected dockers_v2 build args %v", got[1])
+	}
+
+	got, err = configBuildArgs(dir, "Dockerfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0]["SOURCE_DATE_EPOCH"] != "{{ .Env.SOURCE_DATE_EPOCH }}" {
+		t.Errorf("the default Dockerfile build args are %v", got)
+	}
+}
@@ -6,11 +6,29 @@ package internal
 import (
 	"fmt"
 	"path"
+	"slices"
+	"strings"
 
 	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
 )
 
-const containerEphemeralTag = "CONTAINER_IMAGE_EPHEMERAL_TAG={{ if .IsNightly }}nightly{{ else }}latest{{ end }}"
+const (
+	// sourceDateEpoch pins timestamps of reproducible builds to the commit.
+	sourceDateEpoch = "SOURCE_DATE_EPOCH={{ .CommitTimestamp }}"
+	// provenanceTool generates and attaches SLSA provenance, see withProvenance.
+	provenanceTool = "github.com/open-telemetry/opentelemetry-collector-releases/cmd/slsa-provenance"
+	// imageSBOMTool generates and attaches image SBOMs, see withImageSBOMs.
+	imageSBOMTool = "github.com/open-telemetry/opentelemetry-collector-releases/cmd/image-sbom"
+	// nfpmSigningKeyFile is the GPG key embedding signatures into rpm and deb
+	// packages. Packages are unsigned when NFPM_SIGNING_KEY_FILE is not set. The
+	// key passphrase is read from NFPM_PASSPHRASE.
+	nfpmSigningKeyFile = `{{ index .Env "NFPM_SIGNING_KEY_FILE" }}`
+	// componentSBOMTool converts manifest.yaml into an SBOM, see withComponentSBOM.
+	componentSBOMTool = "github.com/open-telemetry/opentelemetry-collector-releases/cmd/manifest-sbom"
+)
+
+// imageSBOMFormats are the syft formats of the SBOMs attached to images.
+var imageSBOMFormats = []string{"spdx-json", "cyclonedx-json"}
 
 // distributionBuilder is used to build distribution configurations.
 type distributionBuilder struct {
@@ -32,6 +50,7 @@ type fullBuildConfig struct {
 	Ppc64Version []string
 	BinaryName   string
 	BuildDir     string
+	Tags         []string
 }
 
 func (c *fullBuildConfig) Build(dist string) config.Build {
@@ -41,11 +60,14 @@ func (c *fullBuildConfig) Build(dist string) config.Build {
 		BuildDetails: config.BuildDetails{
 			Flags:   []string{"{{ .Env.BUILD_FLAGS }}"},
 			Ldflags: []string{"{{ .Env.LD_FLAGS }}"},
+			Tags:    c.Tags,
 		},
 		Goos:    []string{c.TargetOS},
 		Goarch:  c.TargetArch,
 		Goarm:   c.ArmVersion,
 		Goppc64: c.Ppc64Version,
+		// Reproducible binaries, and container image layers copying them.
+		ModTimestamp: "{{ .CommitTimestamp }}",
 	}
 
 	if c.BinaryName != "" {
@@ -96,8 +118,13 @@ type distribution struct {
 	BuildConfigs            []buildConfig
 	Archives                []config.Archive
 	MsiConfig               []config.MSI
+	Chocolateys             []config.Chocolatey
+	Wingets                 []config.Winget
+	Brews                   []config.Homebrew
+	MacOSPkgs               []config.MacOSPkg
 	Nfpms                   []config.NFPM
 	ContainerImages         []config.Docker
+	ContainerImagesV2       []config.DockerV2
 	ContainerImageManifests []config.DockerManifest
 	Signs                   []config.Sign
 	DockerSigns             []config.Sign
@@ -109,6 +136,8 @@ type distribution struct {
 	Release                 config.Release
 	Snapshot                config.Snapshot
 	Changelog               config.Changelog
+	Before                  config.Before
+	After                   config.After
 	Env                     []string
 	EnableCgo               bool
 	LdFlags                 string
@@ -130,8 +159,13 @@ func (d *distribution) buildProject() config.Project {
 		Builds:          builds,
 		Archives:        d.Archives,
 		MSI:             d.MsiConfig,
+		Chocolateys:     d.Chocolateys,
+		Winget:          d.Wingets,
+		Brews:           d.Brews,
+		Pkgs:            d.MacOSPkgs,
 		NFPMs:           d.Nfpms,
 		Dockers:         d.ContainerImages,
+		DockersV2:       d.ContainerImagesV2,
 		DockerManifests: d.ContainerImageManifests,
 		Signs:           d.Signs,
 		DockerSigns:     d.DockerSigns,
@@ -142,6 +176,8 @@ func (d *distribution) buildProject() config.Project {
 		Partial:         d.Partial,
 		Snapshot:        d.Snapshot,
 		Changelog:       d.Changelog,
+		Before:          d.Before,
+		After:           d.After,
 	}
 }
 
@@ -223,11 +259,43 @@ func (b *distributionBuilder) newNfpms(dist string) []config.NFPM {
 					PreRemove:   "preremove.sh",
 				},
 				Contents: nfpmContents,
+				RPM:      config.NFPMRPM{Signature: config.NFPMRPMSignature{KeyFile: nfpmSigningKeyFile}},
+				Deb:      config.NFPMDeb{Signature: config.NFPMDebSignature{KeyFile: nfpmSigningKeyFile}},
 			},
 		},
 	}
 }
 
+// withAIXNfpms adds an RPM for the aix builds, laid out like the AIX Toolbox
+// packages and registering the collector as an SRC subsystem.
+func (b *distributionBuilder) withAIXNfpms() *distributionBuilder {
+	b.configFuncs = append(b.configFuncs, func(d *distribution) {
+		d.Nfpms = append(d.Nfpms, b.newAIXNfpm(d.Name))
+	})
+	return b
+}
+
+func (b *distributionBuilder) newAIXNfpm(dist string) config.NFPM {
+	return config.NFPM{
+		ID:          dist + "-aix",
+		IDs:         []string{dist + "-aix"},
+		Formats:     []string{"rpm"},
+		License:     "Apache 2.0",
+		Description: fmt.Sprintf("OpenTelemetry Collector - %s", dist),
+		Maintainer:  "The OpenTelemetry Collector maintainers <cncf-opentelemetry-maintainers@lists.cncf.io>",
+		Bindir:      path.Join("/opt", "freeware", "bin"),
+		NFPMOverridables: config.NFPMOverridables{
+			PackageName: dist,
+			Scripts: config.NFPMScripts{
+				PreInstall:  "preinstall-aix.sh",
+				PostInstall: "postinstall-aix.sh",
+				PreRemove:   "preremove-aix.sh",
+			},
+			RPM: config.NFPMRPM{Signature: config.NFPMRPMSignature{KeyFile: nfpmSigningKeyFile}},
+		},
+	}
+}
+
 func (b *distributionBuilder) withVarLibDir(user, group string) *distributionBuilder {
 	b.configFuncs = append(b.configFuncs, func(d *distribution) {
 		for i := range d.Nfpms {
@@ -268,6 +336,135 @@ func (b *distributionBuilder) newMSIConfig(dist string) []config.MSI {
 	}
 }
 
+// withDefaultMacOSPkg packages the darwin binaries as .pkg installers. The
+// scripts folder holds the launchd daemon and install scripts rendered by
+// cmd/launchd-generator from the systemd unit.
+func (b *distributionBuilder) withDefaultMacOSPkg() *distributionBuilder {
+	b.configFuncs = append(b.configFuncs, func(d *distribution) {
+		d.MacOSPkgs = b.newMacOSPkgs(d.Name)
+	})
+	return b
+}
+
+func (b *distributionBuilder) newMacOSPkgs(dist string) []config.MacOSPkg {
+	return []config.MacOSPkg{
+		{
+			ID:              dist,
+			IDs:             []string{dist + "-darwin"},
+			Name:            fmt.Sprintf("%s_{{ .Version }}_{{ .Os }}_{{ .Arch }}", dist),
+			Use:             "binary",
+			Identifier:      "io.opentelemetry." + dist,
+			InstallLocation: "/usr/local/bin",
+			Scripts:         "darwin",
+			// pkgbuild is only available on macOS, the release workflows
+			// prepare the darwin targets on macOS runners.
+			If: "{{ eq .Runtime.Goos \"darwin\" }}",
+		},
+	}
+}
+
+// withDefaultWindowsPackageManagers generates Chocolatey and winget manifests
+// wrapping the MSI. cmd/package-params then passes their service arguments to
+// the MSI property. Publishing them is left to the release pipeline.
+func (b *distributionBuilder) withDefaultWindowsPackageManagers() *distributionBuilder {
+	b.configFuncs = append(b.configFuncs, func(d *distribution) {
+		d.Chocolateys = b.newChocolateys(d.Name)
+		d.Wingets = b.newWingets(d.Name)
+	})
+	return b
+}
+
+func (b *distributionBuilder) newChocolateys(dist string) []config.Chocolatey {
+	svcArgs := msiServiceArgsProperty(dist)
+	return []config.Chocolatey{
+		{
+			Name:             dist,
+			IDs:              []string{dist},
+			Use:              "msi",
+			Title:            fmt.Sprintf("OpenTelemetry Collector - %s", dist),
+			Authors:          "The OpenTelemetry Authors",
+			Owners:           "OpenTelemetry",
+			ProjectURL:       projectURL,
+			ProjectSourceURL: repositoryURL,
+			LicenseURL:       repositoryURL + "/blob/main/LICENSE",
+			DocsURL:          docsURL,
+			BugTrackerURL:    repositoryURL + "/issues",
+			Tags:             "opentelemetry otel collector telemetry",
+			Summary:          fmt.Sprintf("OpenTelemetry Collector - %s", dist),
+			Description: fmt.Sprintf(
+				"OpenTelemetry Collector - %s\n\nService arguments can be set with `--params \"'/SvcArgs:...'\"`, passed to the %s MSI property.",
+				dist, svcArgs,
+			),
+			SkipPublish: true,
+		},
+	}
+}
+
+func (b *distributionBuilder) newWingets(dist string) []config.Winget {
+	return []config.Winget{
+		{
+			Name:              dist,
+			PackageIdentifier: fmt.Sprintf("OpenTelemetry.%s", dist),
+			IDs:               []string{dist},
+			Use:               "msi",
+			Publisher:         "OpenTelemetry",
+			PublisherURL:      projectURL,
+			Author:            "The OpenTelemetry Authors",
+			Homepage:          repositoryURL,
+			License:           "Apache-2.0",
+			LicenseURL:        repositoryURL + "/blob/main/LICENSE",
+			ShortDescription:  fmt.Sprintf("OpenTelemetry Collector - %s", dist),
+			InstallationNotes: fmt.Sprintf("Service arguments can be set with `--custom \"%s=...\"`.", msiServiceArgsProperty(dist)),
+			Tags:              []string{"opentelemetry", "otel", "collector", "telemetry"},
+			Repository: config.RepoRef{
+				Owner: "microsoft",
+				Name:  "winget-pkgs",
+			},
+			SkipUpload: "true",
+		},
+	}
+}
+
+// withHomebrew generates a Homebrew formula from the darwin and linux archives.
+// Collector distributions also get a `brew services` definition mirroring the
+// systemd unit shipped in the Linux packages.
+func (b *distributionBuilder) withHomebrew() *distributionBuilder {
+	b.configFuncs = append(b.configFuncs, func(d *distribution) {
+		binary := d.Name
+		for _, buildConfig := range d.BuildConfigs {
+			if buildConfig.OS() == "darwin" {
+				binary = buildConfig.Build(d.Name).Binary
+			}
+		}
+		d.Brews = b.newBrews(d.Name, binary)
+	})
+	return b
+}
+
+func (b *distributionBuilder) newBrews(dist, binary string) []config.Homebrew {
+	brew := config.Homebrew{
+		Name:        binary,
+		Description: fmt.Sprintf("OpenTelemetry Collector - %s", dist),
+		Homepage:    repositoryURL,
+		License:     "Apache-2.0",
+		Directory:   "Formula",
+		Repository: config.RepoRef{
+			Owner: "open-telemetry",
+			Name:  "homebrew-tap",
+		},
+		SkipUpload: "true",
+	}
+	if dist != ocbBinary {
+		configFile := path.Join(dist, "config.yaml")
+		brew.Service = fmt.Sprintf(`run [opt_bin/"%s", "--config", etc/"%s"]
+keep_alive crashed: true
+log_path var/"log/%s.log"
+error_log_path var/"log/%s.log"`, binary, configFile, dist, dist)
+		brew.Caveats = fmt.Sprintf("The %s service reads its configuration from #{etc}/%s.", dist, configFile)
+	}
+	return []config.Homebrew{brew}
+}
+
 func (b *distributionBuilder) withDefaultSigns() *distributionBuilder {
 	b.configFuncs = append(b.configFuncs, func(d *distribution) {
 		d.Signs = b.signs()
@@ -276,16 +473,27 @@ func (b *distributionBuilder) withDefaultSigns() *distributionBuilder {
 }
 
 func (b *distributionBuilder) signs() []config.Sign {
+	s := artifactSigning
+	if !s.cosign() {
+		return []config.Sign{
+			{
+				Artifacts: "all",
+				Signature: s.signature(),
+				Cmd:       "gpg",
+				Args:      s.gpgArgs(),
+			},
+		}
+	}
 	return []config.Sign{
 		{
 			Artifacts: "all",
-			Signature: "${artifact}.sigstore.json",
+			Signature: s.signature(),
 			Cmd:       "cosign",
-			Args: []string{
-				"sign-blob",
-				"--bundle=${signature}",
-				"${artifact}",
-			},
+			Args: slices.Concat(
+				[]string{"sign-blob"},
+				s.cosignKeyArgs(),
+				[]string{"--bundle=${signature}", "${artifact}"},
+			),
 		},
 	}
 }
@@ -298,36 +506,53 @@ func (b *distributionBuilder) withDefaultDockerSigns() *distributionBuilder {
 }
 
 func (b *distributionBuilder) newDockerSigns() []config.Sign {
-	condition := ""
-	switch b.dist.Name {
-	case ocbBinary, opampBinary:
-		condition = "$SKIP_SIGNS != 'true'"
+	s := artifactSigning
+	if !s.cosign() {
+		return nil
 	}
 	return []config.Sign{
 		{
-			If:        condition,
+			If:        b.dockerSignCondition(),
 			Artifacts: "all",
-			Args: []string{
-				"sign",
-				"${artifact}",
-			},
+			Args:      slices.Concat([]string{"sign"}, s.cosignKeyArgs(), []string{"${artifact}"}),
 		},
 	}
 }
 
+// dockerSignCondition returns the condition of image signs. Binaries skip them
+// when their release is not published.
+func (b *distributionBuilder) dockerSignCondition() string {
+	switch b.dist.Name {
+	case ocbBinary, opampBinary:
+		return "$SKIP_SIGNS != 'true'"
+	}
+	return ""
+}
+
 func (b *distributionBuilder) withNightlyConfig() *distributionBuilder {
 	b.configFuncs = append(b.configFuncs, func(d *distribution) {
-		b.dist.Nightly = b.newNightly()
+		c := prereleaseChannel
+		b.dist.Nightly = b.newNightly(c)
+		if c.publishRelease {
+			d.Release.Prerelease = "auto"
+		}
+		if cmd := c.pruneCmd(d.Name); cmd != "" {
+			d.After.Hooks = append(d.After.Hooks, config.Hook{
+				Cmd: cmd,
+				If:  "{{ .IsNightly }}",
+			})
+		}
 	})
 	return b
 }
 
-func (b *distributionBuilder) newNightly() config.Nightly {
+// newNightly configures goreleaser --nightly to release the channel.
+func (b *distributionBuilder) newNightly(c releaseChannel) config.Nightly {
 	return config.Nightly{
-		VersionTemplate:   "{{ incpatch .Version}}-nightly.{{ .ShortCommit }}",
-		TagName:           fmt.Sprintf("nightly-%s", b.dist.Name),
-		PublishRelease:    false,
-		KeepSingleRelease: true,
+		VersionTemplate:   c.versionTemplate,
+		TagName:           c.tagName(b.dist.Name),
+		PublishRelease:    c.publishRelease,
+		KeepSingleRelease: c.retention == 1,
 	}
 }
 
@@ -351,6 +576,136 @@ func (b *distributionBuilder) newSboms() []config.SBOM {
 	}
 }
 
+// withProvenance attaches SLSA v1 provenance to every archive, package and
+// image with cosign, unless signing with GPG. It must come after
+// withDefaultSigns and withDefaultDockerSigns, whose signs it extends.
+func (b *distributionBuilder) withProvenance() *distributionBuilder {
+	b.configFuncs = append(b.configFuncs, func(d *distribution) {
+		d.Signs = append(d.Signs, b.newProvenanceSigns()...)
+		d.DockerSigns = append(d.DockerSigns, b.newProvenanceDockerSigns()...)
+	})
+	return b
+}
+
+func (b *distributionBuilder) provenanceArgs() []string {
+	return []string{
+		"run",
+		provenanceTool,
+		"-distribution=" + b.dist.Name,
+		"-version={{ .Version }}",
+		"-commit={{ .FullCommit }}",
+		"-source={{ .GitURL }}",
+	}
+}
+
+func (b *distributionBuilder) newProvenanceSigns() []config.Sign {
+	s := artifactSigning
+	if !s.cosign() {
+		return nil
+	}
+	var signs []config.Sign
+	for _, artifacts := range []string{"archive", "package"} {
+		signs = append(signs, config.Sign{
+			ID:        "provenance-" + artifacts,
+			Artifacts: artifacts,
+			Signature: s.provenance(),
+			Cmd:       "go",
+			Args: append(b.provenanceArgs(),
+				"-artifact=${artifact}",
+				"-bundle=${signature}",
+			),
+			Env: s.cosignEnv(),
+		})
+	}
+	return signs
+}
+
+func (b *distributionBuilder) newProvenanceDockerSigns() []config.Sign {
+	s := artifactSigning
+	if !s.cosign() {
+		return nil
+	}
+	return []config.Sign{
+		{
+			ID:        "provenance",
+			If:        b.dockerSignCondition(),
+			Artifacts: "all",
+			Cmd:       "go",
+			Args:      append(b.provenanceArgs(), "-image=${artifact}@${digest}"),
+			Env:       s.cosignEnv(),
+		},
+	}
+}
+
+// withImageSBOMs attaches SBOMs in the given syft formats to every image,
+// including the Windows images, as cosign attestations, unless signing with
+// GPG. It must come after withDefaultDockerSigns, whose signs it extends.
+func (b *distributionBuilder) withImageSBOMs(formats ...string) *distributionBuilder {
+	b.configFuncs = append(b.configFuncs, func(d *distribution) {
+		d.DockerSigns = append(d.DockerSigns, b.newImageSBOMSigns(formats)...)
+	})
+	return b
+}
+
+func (b *distributionBuilder) newImageSBOMSigns(formats []string) []config.Sign {
+	s := artifactSigning
+	if !s.cosign() {
+		return nil
+	}
+	var signs []config.Sign
+	for _, format := range formats {
+		signs = append(signs, config.Sign{
+			ID:        "sbom-" + format,
+			If:        b.dockerSignCondition(),
+			Artifacts: "images",
+			Cmd:       "go",
+			Args: []string{
+				"run",
+				imageSBOMTool,
+				"-format=" + format,
+				"-image=${artifact}@${digest}",
+			},
+			Env: s.cosignEnv(),
+		})
+	}
+	return signs
+}
+
+// withComponentSBOM publishes a CycloneDX SBOM of the components listed in the
+// distribution manifest with the release. It must come after withDefaultRelease.
+func (b *distributionBuilder) withComponentSBOM() *distributionBuilder {
+	b.configFuncs = append(b.configFuncs, func(d *distribution) {
+		output := path.Join(defaultBuildDir, "components.cdx.json")
+		d.Before.Hooks = append(d.Before.Hooks, config.Hook{
+			Cmd: fmt.Sprintf("go run %s -manifest=manifest.yaml -version={{ .Version }} -output=%s", componentSBOMTool, output),
+		})
+		d.Release.ExtraFiles = append(d.Release.ExtraFiles, config.ExtraFile{
+			Glob:         output,
+			NameTemplate: d.Name + "_{{ .Version }}_components.cdx.json",
+		})
+	})
+	return b
+}
+
+// withInventory publishes the inventory of the release artifacts and images,
+// generated next to the goreleaser configuration by make generate-goreleaser,
+// rendered for the released version. It must come after the release settings.
+func (b *distributionBuilder) withInventory() *distributionBuilder {
+	b.configFuncs = append(b.configFuncs, func(d *distribution) {
+		src := inventoryFile(d.Name)
+		switch d.Name {
+		case ocbBinary, opampBinary:
+			// Binaries are released from the repository root.
+			src = path.Join("cmd", d.Name, src)
+		}
+		d.Release.TemplatedExtraFiles = append(d.Release.TemplatedExtraFiles, config.TemplatedExtraFile{
+			Source:      src,
+			Destination: d.Name + "_{{ .Version }}_inventory.json",
+		})
+	})
+	return b
+}
+
 func (b *distributionBuilder) withDefaultChecksum() *distributionBuilder {
 	b.configFuncs = append(b.configFuncs, func(d *distribution) {
 		b.dist.Checksum = config.Checksum{
@@ -412,7 +767,8 @@ func (b *distributionBuilder) withDefaultEnv() *distributionBuilder {
 			"COSIGN_YES=true",
 			"LD_FLAGS=" + ldFlags,
 			"BUILD_FLAGS=-trimpath",
-			containerEphemeralTag,
+			prereleaseChannel.ephemeralTagEnv(),
+			sourceDateEpoch,
 			"GOPROXY=https://proxy.golang.org,direct",
 		}
 		if b.dist.GoTags != "" {
@@ -439,6 +795,8 @@ func (b *distributionBuilder) withDefaultRelease() *distributionBuilder {
 	b.configFuncs = append(b.configFuncs, func(d *distribution) {
 		b.dist.Release = config.Release{
 			ReplaceExistingArtifacts: true,
+			// Windows releases on other hosts than defaultWinHost only push images.
+			SkipUpload: fmt.Sprintf("{{ and (eq .Runtime.Goos \"windows\") (ne (envOrDefault \"WIN_HOST_VERSION\" %q) %q) }}", defaultWinHost, defaultWinHost),
 		}
 	})
 	return b
@@ -471,12 +829,20 @@ func (b *distributionBuilder) withPackagingDefaults() *distributionBuilder {
 		withDefaultMonorepo().
 		withDefaultEnv().
 		withDefaultNfpms().
+		withAIXNfpms().
 		withDefaultMSIConfig().
+		withDefaultWindowsPackageManagers().
+		withHomebrew().
+		withDefaultMacOSPkg().
 		withDefaultSigns().
 		withDefaultDockerSigns().
 		withDefaultSBOMs().
+		withProvenance().
+		withImageSBOMs(imageSBOMFormats...).
 		withDefaultPartial().
 		withDefaultRelease().
+		withComponentSBOM().
+		withInventory().
 		withNightlyConfig()
 }
 
@@ -485,15 +851,63 @@ func (b *distributionBuilder) withBinaryPackagingDefaults() *distributionBuilder
 		Disable: "true",
 	}
 	return b.withBinArchive().
+		withHomebrew().
 		withDefaultSnapshot().
 		withDefaultChecksum().
 		withDefaultEnv().
 		withDefaultSigns().
 		withDefaultDockerSigns().
 		withDefaultSBOMs().
+		withProvenance().
+		withImageSBOMs(imageSBOMFormats...).
 		withDefaultBinaryChecksum()
 }
 
+// withFIPS turns a distribution named after its base distribution plus
+// fipsSuffix into a FIPS 140-3 build using the Go Cryptographic Module. It must
+// come last: binaries, archives and images keep the -fips name while packages
+// install the base distribution's service and configuration paths.
+func (b *distributionBuilder) withFIPS() *distributionBuilder {
+	b.configFuncs = append(b.configFuncs, func(d *distribution) {
+		base := strings.TrimSuffix(d.Name, fipsSuffix)
+
+		for _, buildConfig := range d.BuildConfigs {
+			if c, ok := buildConfig.(*fullBuildConfig); ok {
+				c.Tags = append(c.Tags, fipsBuildTag)
+			}
+		}
+		// GOFIPS140 also defaults the binaries to GODEBUG=fips140=on.
+		d.Env = append(d.Env, "GOFIPS140="+fipsModuleVersion)
+
+		// The images are built from the base Dockerfile, copying the -fips binary.
+		for i := range d.ContainerImages {
+			d.ContainerImages[i].BuildFlagTemplates = append(d.ContainerImages[i].BuildFlagTemplates, "--build-arg=BINARY="+d.Name)
+		}
+		for i := range d.ContainerImagesV2 {
+			d.ContainerImagesV2[i].BuildArgs["BINARY"] = d.Name
+		}
+
+		for i, nfpm := range d.Nfpms {
+			for j, content := range nfpm.Contents {
+				content.Source = strings.ReplaceAll(content.Source, d.Name, base)
+				content.Destination = strings.ReplaceAll(content.Destination, d.Name, base)
+				nfpm.Contents[j] = content
+			}
+			// The systemd unit starts the base binary name.
+			nfpm.Contents = append(nfpm.Contents, config.NFPMContent{
+				Source:      path.Join("/usr", "bin", d.Name),
+				Destination: path.Join("/usr", "bin", base),
+				Type:        "symlink",
+			})
+			nfpm.Conflicts = append(nfpm.Conflicts, base)
+			nfpm.Replaces = append(nfpm.Replaces, base)
+			nfpm.Provides = append(nfpm.Provides, base)
+			d.Nfpms[i] = nfpm
+		}
+	})
+	return b
+}
+
 // withConfigFunc adds a configuration function to the builder.
 func (b *distributionBuilder) withConfigFunc(configFunc func(*distribution)) *distributionBuilder {
 	b.configFuncs = append(b.configFuncs, configFunc)
@@ -506,6 +920,10 @@ func (b *distributionBuilder) withDefaultConfigIncluded() *distributionBuilder {
 			container.Files = append(container.Files, "config.yaml")
 			d.ContainerImages[i] = container
 		}
+		for i, container := range d.ContainerImagesV2 {
+			container.ExtraFiles = append(container.ExtraFiles, "config.yaml")
+			d.ContainerImagesV2[i] = container
+		}
 
 		for i, nfpm := range d.Nfpms {
 			nfpm.Contents = append(nfpm.Contents, config.NFPMContent{
@@ -519,6 +937,21 @@ func (b *distributionBuilder) withDefaultConfigIncluded() *distributionBuilder {
 		for i := range d.MsiConfig {
 			d.MsiConfig[i].Files = append(d.MsiConfig[i].Files, "config.yaml")
 		}
+
+		if len(d.Brews) > 0 {
+			// Homebrew installs the default configuration from the archive, so
+			// ship it there too. Setting files replaces goreleaser's defaults,
+			// hence README* is listed explicitly.
+			for i := range d.Archives {
+				d.Archives[i].Files = append(d.Archives[i].Files,
+					config.File{Source: "README*"},
+					config.File{Source: "config.yaml"},
+				)
+			}
+			for i := range d.Brews {
+				d.Brews[i].ExtraInstall = fmt.Sprintf(`(etc/"%s").install "config.yaml"`, d.Name)
+			}
+		}
 	})
 	return b
 }
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// pruneReleasesTool deletes the releases of a channel beyond its retention.
+const pruneReleasesTool = "github.com/open-telemetry/opentelemetry-collector-releases/cmd/prune-releases"
+
+// releaseChannel describes how the releases of a channel are versioned, tagged
+// and kept. Stable releases are versioned from their git tag. The releases of
+// the other channels are cut from any commit with goreleaser --nightly.
+type releaseChannel struct {
+	name string
+	// versionTemplate derives the version from the latest tag, e.g. 0.160.1-nightly.abc1234.
+	versionTemplate string
+	// ephemeralTag is the image tag following the latest release of the channel.
+	ephemeralTag string
+	// retention is the number of GitHub releases kept, all of them if 0.
+	retention int
+	// publishRelease publishes GitHub releases, as prereleases. Without it,
+	// only the images are pushed.
+	publishRelease bool
+}
+
+var (
+	stableChannel = releaseChannel{
+		name:           "stable",
+		ephemeralTag:   "latest",
+		publishRelease: true,
+	}
+	rcChannel = releaseChannel{
+		name:            "rc",
+		versionTemplate: "{{ incminor .Version }}-rc.{{ .ShortCommit }}",
+		ephemeralTag:    "rc",
+		retention:       3,
+		publishRelease:  true,
+	}
+	betaChannel = releaseChannel{
+		name:            "beta",
+		versionTemplate: "{{ incminor .Version }}-beta.{{ .ShortCommit }}",
+		ephemeralTag:    "beta",
+		retention:       5,
+		publishRelease:  true,
+	}
+	nightlyChannel = releaseChannel{
+		name:            "nightly",
+		versionTemplate: "{{ incpatch .Version}}-nightly.{{ .ShortCommit }}",
+		ephemeralTag:    "nightly",
+		retention:       1,
+	}
+
+	releaseChannels = []releaseChannel{stableChannel, rcChannel, betaChannel, nightlyChannel}
+
+	// prereleaseChannel is the channel released with goreleaser --nightly.
+	prereleaseChannel = nightlyChannel
+)
+
+// UseReleaseChannel selects the channel released with goreleaser --nightly,
+// nightly by default. Stable releases are always configured. It must be called
+// before building a distribution.
+func UseReleaseChannel(name string) error {
+	i := slices.IndexFunc(releaseChannels, func(c releaseChannel) bool { return c.name == name })
+	if i < 0 {
+		var names []string
+		for _, c := range releaseChannels {
+			names = append(names, c.name)
+		}
+		return fmt.Errorf("unknown release channel %q, want one of %s", name, strings.Join(names, ", "))
+	}
+	c := releaseChannels[i]
+	if c.versionTemplate == "" {
+		return fmt.Errorf("%s releases are versioned from their tag and always configured", c.name)
+	}
+	prereleaseChannel = c
+	return nil
+}
+
+// ephemeralTagEnv returns the env setting CONTAINER_IMAGE_EPHEMERAL_TAG to the
+// ephemeral tag of the channel being released.
+func (c releaseChannel) ephemeralTagEnv() string {
+	return fmt.Sprintf("CONTAINER_IMAGE_EPHEMERAL_TAG={{ if .IsNightly }}%s{{ else }}%s{{ end }}", c.ephemeralTag, stableChannel.ephemeralTag)
+}
+
+// tagName returns the git tag of the channel releases of a distribution. A
+// single kept release is moved along, others are tagged per version.
+func (c releaseChannel) tagName(dist string) string {
+	if c.retention == 1 {
+		return fmt.Sprintf("%s-%s", c.name, dist)
+	}
+	return fmt.Sprintf("%s-%s-{{ .Version }}", c.name, dist)
+}
+
+// pruneCmd returns the command deleting the releases beyond the retention,
+// empty when goreleaser keeps them itself.
+func (c releaseChannel) pruneCmd(dist string) string {
+	if !c.publishRelease || c.retention <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("go run %s -tag-prefix=%s-%s- -keep=%d", pruneReleasesTool, c.name, dist, c.retention)
+}
@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+	"go.yaml.in/yaml/v3"
+)
+
+func TestUseReleaseChannel(t *testing.T) {
+	t.Cleanup(func() { prereleaseChannel = nightlyChannel })
+
+	tests := []struct {
+		channel        string
+		wantVersion    string
+		wantTag        string
+		wantPublish    bool
+		wantPrerelease string
+		wantPrune      string
+		wantEphemeral  string
+	}{
+		{
+			channel:       "nightly",
+			wantVersion:   "{{ incpatch .Version}}-nightly.{{ .ShortCommit }}",
+			wantTag:       "nightly-otelcol",
+			wantEphemeral: "nightly",
+		},
+		{
+			channel:        "rc",
+			wantVersion:    "{{ incminor .Version }}-rc.{{ .ShortCommit }}",
+			wantTag:        "rc-otelcol-{{ .Version }}",
+			wantPublish:    true,
+			wantPrerelease: "auto",
+			wantPrune:      "go run " + pruneReleasesTool + " -tag-prefix=rc-otelcol- -keep=3",
+			wantEphemeral:  "rc",
+		},
+		{
+			channel:        "beta",
+			wantVersion:    "{{ incminor .Version }}-beta.{{ .ShortCommit }}",
+			wantTag:        "beta-otelcol-{{ .Version }}",
+			wantPublish:    true,
+			wantPrerelease: "auto",
+			wantPrune:      "go run " + pruneReleasesTool + " -tag-prefix=beta-otelcol- -keep=5",
+			wantEphemeral:  "beta",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.channel, func(t *testing.T) {
+			if err := UseReleaseChannel(tt.channel); err != nil {
+				t.Fatal(err)
+			}
+			project := newDistributionBuilder(coreDistro).
+				withDefaultEnv().
+				withDefaultRelease().
+				withNightlyConfig().
+				build()
+
+			n := project.Nightly
+			if n.VersionTemplate != tt.wantVersion || n.TagName != tt.wantTag || n.PublishRelease != tt.wantPublish {
+				t.Errorf("nightly = %q %q publish %v, want %q %q publish %v",
+					n.VersionTemplate, n.TagName, n.PublishRelease, tt.wantVersion, tt.wantTag, tt.wantPublish)
+			}
+			if got := project.Release.Prerelease; got != tt.wantPrerelease {
+				t.Errorf("release.prerelease = %q, want %q", got, tt.wantPrerelease)
+			}
+
+			var wantHooks []config.Hook
+			if tt.wantPrune != "" {
+				wantHooks = []config.Hook{{Cmd: tt.wantPrune, If: "{{ .IsNightly }}"}}
+			}
+			if !slices.EqualFunc(project.After.Hooks, wantHooks, func(a, b config.Hook) bool { return a.Cmd == b.Cmd && a.If == b.If }) {
+				t.Errorf("after hooks = %+v, want %+v", project.After.Hooks, wantHooks)
+			}
+
+			wantEnv := "CONTAINER_IMAGE_EPHEMERAL_TAG={{ if .IsNightly }}" + tt.wantEphemeral + "{{ else }}latest{{ end }}"
+			if !slices.Contains(project.Env, wantEnv) {
+				t.Errorf("env %q doesn't contain %q", project.Env, wantEnv)
+			}
+		})
+	}
+}
+
+// TestReleaseChannelConfigs checks the committed channel configs, which
+// the channel release workflow runs goreleaser --nightly with.
+func TestReleaseChannelConfigs(t *testing.T) {
+	chdirRoot(t)
+
+	for _, c := range []releaseChannel{rcChannel, betaChannel} {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := os.ReadFile("distributions/otelcol/.goreleaser-" + c.name + ".yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var project config.Project
+			if err := yaml.Unmarshal(data, &project); err != nil {
+				t.Fatal(err)
+			}
+
+			if want := c.name + "-otelcol-{{ .Version }}"; project.Nightly.TagName != want {
+				t.Errorf("nightly.tag_name = %q, want %q", project.Nightly.TagName, want)
+			}
+			if !project.Nightly.PublishRelease {
+				t.Error("nightly.publish_release isn't set")
+			}
+			if project.Release.Prerelease != "auto" {
+				t.Errorf("release.prerelease = %q, want auto", project.Release.Prerelease)
+			}
+			prune := c.pruneCmd(coreDistro)
+			if !slices.ContainsFunc(project.After.Hooks, func(h config.Hook) bool { return h.Cmd == prune && h.If == "{{ .IsNightly }}" }) {
+				t.Errorf("no prune hook %q in %+v", prune, project.After.Hooks)
+			}
+		})
+	}
+}
+
+func TestUseReleaseChannelErrors(t *testing.T) {
+	t.Cleanup(func() { prereleaseChannel = nightlyChannel })
+
+	for _, name := range []string{"stable", "alpha", ""} {
+		if err := UseReleaseChannel(name); err == nil {
+			t.Errorf("UseReleaseChannel(%q) succeeded", name)
+		}
+	}
+	if prereleaseChannel.name != nightlyChannel.name {
+		t.Errorf("invalid channels changed the channel to %s", prereleaseChannel.name)
+	}
+}
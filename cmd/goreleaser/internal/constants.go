@@ -16,4 +16,15 @@ const (
 	imageNamePrefix  = "opentelemetry-collector"
 	projectName      = "opentelemetry-collector-releases"
 	defaultBuildDir  = "_build"
+
+	fipsSuffix = "-fips"
+	// fipsBuildTag lets the collector report that it runs in FIPS 140-3 mode.
+	fipsBuildTag = "fips"
+	// fipsModuleVersion is the Go Cryptographic Module version submitted for
+	// FIPS 140-3 validation, selected through GOFIPS140.
+	fipsModuleVersion = "v1.0.0"
+
+	projectURL    = "https://opentelemetry.io/"
+	docsURL       = "https://opentelemetry.io/docs/collector/"
+	repositoryURL = "https://github.com/open-telemetry/opentelemetry-collector-releases"
 )
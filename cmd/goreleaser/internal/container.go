@@ -5,26 +5,121 @@ package internal
 
 import (
 	"fmt"
+	"maps"
 	"slices"
+	"strings"
+	"text/template"
 
 	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
 )
 
 const (
 	armArchitecture = "arm"
-	dockerHubRepo   = "otel"
-	ghcrRepo        = "ghcr.io/open-telemetry/opentelemetry-collector-releases"
+	// winBaseNanoserver and winBaseServercore are the Windows base image families.
+	winBaseNanoserver = "nanoserver"
+	winBaseServercore = "servercore"
+	// floatingTag is the MAJOR.MINOR tag that follows the latest patch release.
+	floatingTag = "{{ .Major }}.{{ .Minor }}"
+	// skipFloatingTag keeps nightly and snapshot builds from moving floating tags.
+	skipFloatingTag = "{{ or (eq .Runtime.Goos \"windows\") .IsNightly .IsSnapshot }}"
+	// skipOnWindows pushes linux manifest lists from the linux release only,
+	// which runs after the windows release has pushed the windows images.
+	skipOnWindows = "{{ eq .Runtime.Goos \"windows\" }}"
+	dockerHubRepo = "otel"
+	ghcrRepo      = "ghcr.io/open-telemetry/opentelemetry-collector-releases"
 )
 
 var (
+	// reproducibleBuildFlags make buildx set the image creation time and the
+	// layer file timestamps to SOURCE_DATE_EPOCH, the commit time.
+	reproducibleBuildFlags = []string{
+		"--build-arg=SOURCE_DATE_EPOCH={{ .Env.SOURCE_DATE_EPOCH }}",
+		"--output=type=docker,rewrite-timestamp=true",
+	}
+
 	imageRepositories = []string{dockerHubRepo, ghcrRepo}
+	// imageNameTemplate, when set, renders image names from imageNameData.
+	imageNameTemplate *template.Template
+	// imageExtraTags are goreleaser tag templates published in addition to the
+	// version and ephemeral tags, e.g. "{{ .Major }}.{{ .Minor }}".
+	imageExtraTags []string
+	// dockersV2 builds linux images with one multi-platform dockers_v2 entry
+	// per image instead of per-arch images and manifest lists.
+	dockersV2 bool
+	// dockersV2ArchTags additionally publishes the per-arch tags with dockers_v2.
+	dockersV2ArchTags bool
+
+	// debugVariant ships a busybox shell, through the alpine base image, for troubleshooting.
+	debugVariant = imageVariant{name: "debug", dockerfile: "Debug.dockerfile"}
+	// distrolessVariant runs as the distroless nonroot user, with matching passwd entries.
+	distrolessVariant = imageVariant{name: "distroless", dockerfile: "Distroless.dockerfile"}
 )
 
+// imageNameData is passed to the image name template.
+type imageNameData struct {
+	// Distribution is the distribution or binary name, e.g. otelcol-contrib.
+	Distribution string
+	// Name is the default image name, e.g. opentelemetry-collector-contrib.
+	Name string
+}
+
+// ConfigureImages overrides the repositories container images are published
+// to, the template used to name them and additional tags. Empty values keep the
+// defaults. It must be called before building a distribution.
+func ConfigureImages(repositories []string, nameTemplate string, extraTags []string) error {
+	if len(repositories) > 0 {
+		imageRepositories = repositories
+	}
+	if nameTemplate != "" {
+		tmpl, err := template.New("image-name").Option("missingkey=error").Parse(nameTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid image name template: %w", err)
+		}
+		imageNameTemplate = tmpl
+	}
+	imageExtraTags = extraTags
+	return nil
+}
+
+// UseDockersV2 builds linux images with goreleaser dockers_v2, one buildx
+// invocation per image for all platforms. The per-arch tags, e.g.
+// 0.159.0-amd64, are only published with archTags. Windows images are still
+// built per version with dockers and are not part of multi-OS manifest lists.
+// It must be called before building a distribution.
+func UseDockersV2(archTags bool) {
+	dockersV2 = true
+	dockersV2ArchTags = archTags
+}
+
+// imageTags returns the tags every image and manifest is published with.
+func imageTags() []string {
+	return slices.Concat([]string{`{{ .Version }}`, "{{ .Env.CONTAINER_IMAGE_EPHEMERAL_TAG }}"}, imageExtraTags)
+}
+
+// imageVariant is an alternative flavour of a Linux image, built from its own
+// Dockerfile and published under tags suffixed with its name.
+type imageVariant struct {
+	name       string
+	dockerfile string
+	buildArgs  map[string]string
+	// labels are added to the org.opencontainers.image labels.
+	labels map[string]string
+}
+
 // containerImageOptions contains options for container image configuration.
 type containerImageOptions struct {
 	armVersion    string
 	winVersion    string
 	binaryRelease bool
+	variant       imageVariant
+	// floatingTags publishes MAJOR.MINOR manifests for linux images.
+	floatingTags bool
+	// winVersions are the Windows Server versions windows images are built
+	// for. On linux manifests, the windows images of these versions are added,
+	// turning them into multi-OS manifest lists.
+	winVersions []string
+	// winBase is the Windows base image family, nanoserver by default.
+	winBase string
 }
 
 func (o *containerImageOptions) version() string {
@@ -34,8 +129,32 @@ func (o *containerImageOptions) version() string {
 	return o.winVersion
 }
 
+// tagSuffix returns the suffix identifying the image variant in manifest tags.
+func (o *containerImageOptions) tagSuffix() string {
+	if o.variant.name == "" {
+		return ""
+	}
+	return "-" + o.variant.name
+}
+
+// windowsBase returns the Windows base image family.
+func (o *containerImageOptions) windowsBase() string {
+	if o.winBase == "" {
+		return winBaseNanoserver
+	}
+	return o.winBase
+}
+
+// windowsTag returns the tag infix for windows images, e.g. windows-servercore.
+func (o *containerImageOptions) windowsTag() string {
+	if o.windowsBase() == winBaseNanoserver {
+		return "windows"
+	}
+	return "windows-" + o.windowsBase()
+}
+
 type osArchInfo struct {
-	os, arch, version string
+	os, arch, version, variant, winTag string
 }
 
 func (o *osArchInfo) buildPlatform() string {
@@ -54,19 +173,39 @@ func (o *osArchInfo) buildPlatform() string {
 func (o *osArchInfo) imageTag() string {
 	switch o.os {
 	case "linux":
-		switch o.arch {
-		case armArchitecture:
-			return fmt.Sprintf("armv%s", o.version)
+		tag := o.arch
+		if o.arch == armArchitecture {
+			tag = fmt.Sprintf("armv%s", o.version)
+		}
+		if o.variant != "" {
+			return fmt.Sprintf("%s-%s", o.variant, tag)
 		}
+		return tag
 	case "windows":
-		return fmt.Sprintf("windows-%s-%s", o.version, o.arch)
+		winTag := o.winTag
+		if winTag == "" {
+			winTag = "windows"
+		}
+		return fmt.Sprintf("%s-%s-%s", winTag, o.version, o.arch)
 	}
 	return o.arch
 }
 
-// newContainerImages creates container image configurations.
+// newContainerImages creates container image configurations. Windows images
+// are created for every version in opts.winVersions.
 func newContainerImages(dist string, targetOS string, targetArchs []string, opts containerImageOptions) []config.Docker {
+	if dockersV2 && targetOS == "linux" {
+		return nil
+	}
 	var images []config.Docker
+	if targetOS == "windows" && opts.winVersion == "" {
+		for _, winVersion := range opts.winVersions {
+			versionOpts := opts
+			versionOpts.winVersion = winVersion
+			images = append(images, newContainerImages(dist, targetOS, targetArchs, versionOpts)...)
+		}
+		return images
+	}
 	for _, targetArch := range targetArchs {
 		images = append(images, buildDockerImageWithOS(dist, targetOS, targetArch, opts))
 	}
@@ -74,96 +213,325 @@ func newContainerImages(dist string, targetOS string, targetArchs []string, opts
 }
 
 // newContainerImageManifests creates container image manifest configurations.
+// Windows manifests list the images of every version in opts.winVersions.
 func newContainerImageManifests(dist, os string, archs []string, opts containerImageOptions) []config.DockerManifest {
-	tags := []string{`{{ .Version }}`, "{{ .Env.CONTAINER_IMAGE_EPHEMERAL_TAG }}"}
-	if os == "windows" {
-		for i, tag := range tags {
-			tags[i] = fmt.Sprintf("%s-%s-%s", tag, os, opts.winVersion)
-		}
+	if dockersV2 && os == "linux" {
+		return nil
 	}
-
 	var r []config.DockerManifest
 	for _, imageRepo := range imageRepositories {
-		for _, tag := range tags {
-			r = append(r, buildOSDockerManifest(imageRepo, tag, dist, os, archs, opts))
+		for _, tag := range imageTags() {
+			if os == "windows" {
+				r = append(r, buildWindowsDockerManifest(imageRepo, tag, dist, archs, opts))
+				continue
+			}
+			manifest := buildOSDockerManifest(imageRepo, tag, dist, os, archs, opts)
+			if len(opts.winVersions) > 0 {
+				// Nightly releases have no windows images and push the linux list instead.
+				manifest.SkipPush = "{{ or (eq .Runtime.Goos \"windows\") (not .IsNightly) }}"
+				r = append(r, manifest, buildMultiOSDockerManifest(imageRepo, tag, dist, archs, opts))
+				continue
+			}
+			r = append(r, manifest)
+		}
+		// The floating tag lists the same images as the version tag, so that
+		// Windows nodes pulling it find theirs too.
+		if opts.floatingTags && os == "linux" {
+			manifest := buildOSDockerManifest(imageRepo, `{{ .Version }}`, dist, os, archs, opts)
+			if len(opts.winVersions) > 0 {
+				manifest = buildMultiOSDockerManifest(imageRepo, `{{ .Version }}`, dist, archs, opts)
+			}
+			manifest.NameTemplate = fmt.Sprintf("%s/%s:%s%s", imageRepo, imageName(dist, opts), floatingTag, opts.tagSuffix())
+			manifest.SkipPush = skipFloatingTag
+			r = append(r, manifest)
 		}
 	}
 	return r
 }
 
 func buildDockerImageWithOS(dist, os, arch string, opts containerImageOptions) config.Docker {
-	osArch := osArchInfo{os: os, arch: arch, version: opts.version()}
+	osArch := osArchInfo{os: os, arch: arch, version: opts.version(), variant: opts.variant.name, winTag: opts.windowsTag()}
 	var imageTemplates []string
 	for _, prefix := range imageRepositories {
-		imageTemplates = append(
-			imageTemplates,
-			fmt.Sprintf("%s/%s:{{ .Version }}-%s", prefix, imageName(dist, opts), osArch.imageTag()),
-			fmt.Sprintf("%s/%s:{{ .Env.CONTAINER_IMAGE_EPHEMERAL_TAG }}-%s", prefix, imageName(dist, opts), osArch.imageTag()),
-		)
+		for _, tag := range imageTags() {
+			imageTemplates = append(
+				imageTemplates,
+				fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist, opts), tag, osArch.imageTag()),
+			)
+		}
 	}
 
-	label := func(name, template string) string {
-		return fmt.Sprintf("--label=org.opencontainers.image.%s={{%s}}", name, template)
-	}
 	imageConfig := config.Docker{
 		ImageTemplates: imageTemplates,
 		Dockerfile:     "Dockerfile",
 		Use:            "buildx",
-		BuildFlagTemplates: []string{
+		BuildFlagTemplates: slices.Concat([]string{
 			"--pull",
 			fmt.Sprintf("--platform=%s", osArch.buildPlatform()),
-			label("created", ".Date"),
-			label("name", ".ProjectName"),
-			label("revision", ".FullCommit"),
-			label("version", ".Version"),
-			label("source", ".GitURL"),
-			"--label=org.opencontainers.image.licenses=Apache-2.0",
-		},
+		}, reproducibleBuildFlags),
 		Goos:   os,
 		Goarch: arch,
 	}
+	if opts.variant.dockerfile != "" {
+		imageConfig.Dockerfile = opts.variant.dockerfile
+	}
 	if arch == armArchitecture {
 		imageConfig.Goarm = opts.armVersion
 	}
+	buildArgs := maps.Clone(opts.variant.buildArgs)
+	if buildArgs == nil {
+		buildArgs = map[string]string{}
+	}
+	for _, key := range slices.Sorted(maps.Keys(opts.variant.buildArgs)) {
+		imageConfig.BuildFlagTemplates = append(
+			imageConfig.BuildFlagTemplates,
+			fmt.Sprintf("--build-arg=%s=%s", key, opts.variant.buildArgs[key]),
+		)
+	}
 	if os == "windows" {
+		buildArgs["WIN_VERSION"] = opts.winVersion
+		buildArgs["WIN_BASE"] = opts.windowsBase()
+		buildArgs["WIN_IMAGE"] = winBaseImages[opts.windowsBase()+":"+opts.winVersion]
 		imageConfig.BuildFlagTemplates = slices.Insert(
 			imageConfig.BuildFlagTemplates, 1,
 			fmt.Sprintf("--build-arg=WIN_VERSION=%s", opts.winVersion),
+			fmt.Sprintf("--build-arg=WIN_BASE=%s", opts.windowsBase()),
+			fmt.Sprintf("--build-arg=WIN_IMAGE=%s", buildArgs["WIN_IMAGE"]),
 		)
+		// The classic Windows builder doesn't support the buildx reproducibility flags.
+		imageConfig.BuildFlagTemplates = slices.DeleteFunc(imageConfig.BuildFlagTemplates, func(flag string) bool {
+			return slices.Contains(reproducibleBuildFlags, flag)
+		})
 		imageConfig.Dockerfile = "Windows.dockerfile"
 		imageConfig.Use = "docker"
-		imageConfig.SkipBuild = "{{ not (eq .Runtime.Goos \"windows\") }}"
-		imageConfig.SkipPush = "{{ not (eq .Runtime.Goos \"windows\") }}"
+		imageConfig.SkipBuild = skipOffWinHost(winContainerHosts[opts.winVersion])
+		imageConfig.SkipPush = imageConfig.SkipBuild
+	}
+	for _, label := range imageLabels(dist, imageConfig.Dockerfile, buildArgs) {
+		imageConfig.BuildFlagTemplates = append(
+			imageConfig.BuildFlagTemplates,
+			fmt.Sprintf("--label=org.opencontainers.image.%s=%s", label.key, label.value),
+		)
+	}
+	for _, key := range slices.Sorted(maps.Keys(opts.variant.labels)) {
+		imageConfig.BuildFlagTemplates = append(
+			imageConfig.BuildFlagTemplates,
+			fmt.Sprintf("--label=%s=%s", key, opts.variant.labels[key]),
+		)
 	}
 	return imageConfig
 }
 
+// imageLabel is an org.opencontainers.image label, without the prefix.
+type imageLabel struct {
+	key, value string
+}
+
+// imageLabels returns the org.opencontainers.image labels of an image. The
+// manifest lists are annotated with them, except created and name, see
+// indexAnnotation.
+func imageLabels(dist, dockerfile string, buildArgs map[string]string) []imageLabel {
+	labels := []imageLabel{
+		{"created", "{{.CommitDate}}"},
+		{"name", "{{.ProjectName}}"},
+		{"revision", "{{.FullCommit}}"},
+		{"version", "{{.Version}}"},
+		{"source", "{{.GitURL}}"},
+		{"licenses", "Apache-2.0"},
+		{"documentation", docsURL},
+		{"vendor", vendor},
+	}
+	if description := sourcesMetadata[sourceDir(dist)].description; description != "" {
+		labels = append(labels, imageLabel{"description", description})
+	}
+	baseName, baseDigest := imageBase(dist, dockerfile, buildArgs)
+	if baseName != "" {
+		labels = append(labels, imageLabel{"base.name", baseName})
+	}
+	if baseDigest != "" {
+		labels = append(labels, imageLabel{"base.digest", baseDigest})
+	}
+	return labels
+}
+
+// indexAnnotation reports whether an image label is also an annotation of the
+// manifest lists: created and name differ between the images of a list.
+func indexAnnotation(label imageLabel) bool {
+	return label.key != "created" && label.key != "name"
+}
+
 func buildOSDockerManifest(prefix, version, dist, os string, archs []string, opts containerImageOptions) config.DockerManifest {
 	var imageTemplates []string
 	for _, arch := range archs {
 		switch arch {
 		case armArchitecture:
 			for _, armVers := range armVersions(dist) {
-				dockerArchTag := (&osArchInfo{os: os, arch: arch, version: armVers}).imageTag()
+				dockerArchTag := (&osArchInfo{os: os, arch: arch, version: armVers, variant: opts.variant.name}).imageTag()
 				imageTemplates = append(
 					imageTemplates,
 					fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist, opts), version, dockerArchTag),
 				)
 			}
 		default:
+			dockerArchTag := (&osArchInfo{os: os, arch: arch, variant: opts.variant.name}).imageTag()
 			imageTemplates = append(
 				imageTemplates,
-				fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist, opts), version, arch),
+				fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist, opts), version, dockerArchTag),
 			)
 		}
 	}
 
 	manifest := config.DockerManifest{
-		NameTemplate:   fmt.Sprintf("%s/%s:%s", prefix, imageName(dist, opts), version),
+		NameTemplate:   fmt.Sprintf("%s/%s:%s%s", prefix, imageName(dist, opts), version, opts.tagSuffix()),
 		ImageTemplates: imageTemplates,
+		SkipPush:       skipOnWindows,
+		// docker manifest can't annotate the list, podman sets the annotations
+		// when creating it, before goreleaser pushes and signs it.
+		Use: "podman",
 	}
-	if os == "windows" {
-		manifest.SkipPush = "{{ not (eq .Runtime.Goos \"windows\") }}"
+	dockerfile := "Dockerfile"
+	if opts.variant.dockerfile != "" {
+		dockerfile = opts.variant.dockerfile
+	}
+	for _, label := range imageLabels(dist, dockerfile, opts.variant.buildArgs) {
+		if indexAnnotation(label) {
+			manifest.CreateFlags = append(
+				manifest.CreateFlags,
+				fmt.Sprintf("--annotation=org.opencontainers.image.%s=%s", label.key, label.value),
+			)
+		}
+	}
+	return manifest
+}
+
+// windowsImageTemplates returns the windows images of every configured version.
+func windowsImageTemplates(prefix, tag, dist string, archs []string, opts containerImageOptions) []string {
+	var imageTemplates []string
+	for _, winVersion := range opts.winVersions {
+		for _, arch := range archs {
+			osArch := osArchInfo{os: "windows", arch: arch, version: winVersion, winTag: opts.windowsTag()}
+			imageTemplates = append(
+				imageTemplates,
+				fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist, opts), tag, osArch.imageTag()),
+			)
+		}
+	}
+	return imageTemplates
+}
+
+// buildWindowsDockerManifest creates a manifest list with the windows images of
+// every configured version, letting nodes pick theirs through os.version. It is
+// pushed by the runner of the last version, which the release workflows run
+// after the others.
+func buildWindowsDockerManifest(prefix, tag, dist string, archs []string, opts containerImageOptions) config.DockerManifest {
+	return config.DockerManifest{
+		NameTemplate:   fmt.Sprintf("%s/%s:%s-%s", prefix, imageName(dist, opts), tag, opts.windowsTag()),
+		ImageTemplates: windowsImageTemplates(prefix, tag, dist, archs, opts),
+		SkipPush:       skipOffWinHost(winContainerHosts[opts.winVersions[len(opts.winVersions)-1]]),
 	}
+}
+
+// skipOffWinHost builds and pushes windows images and manifest lists from the
+// windows release running on the given Windows Server host only.
+func skipOffWinHost(host string) string {
+	return fmt.Sprintf("{{ or (not (eq .Runtime.Goos \"windows\")) (ne (envOrDefault \"WIN_HOST_VERSION\" %q) %q) }}", defaultWinHost, host)
+}
+
+// buildMultiOSDockerManifest creates a manifest list with the linux images and
+// the windows images of every configured version, pushed by the linux release
+// once the windows release has pushed the windows images. Docker reads each
+// entry's platform, including os.version, from the image config.
+func buildMultiOSDockerManifest(prefix, tag, dist string, archs []string, opts containerImageOptions) config.DockerManifest {
+	manifest := buildOSDockerManifest(prefix, tag, dist, "linux", archs, opts)
+	manifest.ImageTemplates = append(manifest.ImageTemplates, windowsImageTemplates(prefix, tag, dist, winContainerArchs, opts)...)
+	manifest.SkipPush = "{{ or (eq .Runtime.Goos \"windows\") .IsNightly }}"
 	return manifest
 }
+
+// newContainerImagesV2 creates the dockers_v2 configurations of a linux image,
+// when enabled through UseDockersV2. Binaries are laid out per platform in the
+// build context, which the Dockerfiles pick up through PLATFORM_DIRS.
+func newContainerImagesV2(dist string, archs []string, opts containerImageOptions) []config.DockerV2 {
+	if !dockersV2 {
+		return nil
+	}
+
+	var platforms []string
+	for _, arch := range archs {
+		if arch == armArchitecture {
+			for _, armVers := range armVersions(dist) {
+				platforms = append(platforms, (&osArchInfo{os: "linux", arch: arch, version: armVers}).buildPlatform())
+			}
+			continue
+		}
+		platforms = append(platforms, (&osArchInfo{os: "linux", arch: arch}).buildPlatform())
+	}
+
+	var tags []string
+	for _, tag := range imageTags() {
+		tags = append(tags, tag+opts.tagSuffix())
+	}
+	if opts.floatingTags {
+		// Empty tags are not published.
+		tags = append(tags, fmt.Sprintf("{{ if not (or .IsNightly .IsSnapshot) }}%s%s{{ end }}", floatingTag, opts.tagSuffix()))
+	}
+
+	image := config.DockerV2{
+		ID:         imageName(dist, opts) + opts.tagSuffix(),
+		Dockerfile: "Dockerfile",
+		Platforms:  platforms,
+		Tags:       tags,
+		BuildArgs: map[string]string{
+			"PLATFORM_DIRS":     "true",
+			"SOURCE_DATE_EPOCH": "{{ .Env.SOURCE_DATE_EPOCH }}",
+		},
+		// Provenance attestations record the build time, the image is attested separately.
+		Flags: []string{"--pull", "--provenance=false", "--output=type=image,rewrite-timestamp=true"},
+	}
+	for _, imageRepo := range imageRepositories {
+		image.Images = append(image.Images, fmt.Sprintf("%s/%s", imageRepo, imageName(dist, opts)))
+	}
+	if opts.variant.dockerfile != "" {
+		image.Dockerfile = opts.variant.dockerfile
+	}
+	maps.Copy(image.BuildArgs, opts.variant.buildArgs)
+	image.Labels = maps.Clone(opts.variant.labels)
+	if image.Labels == nil {
+		image.Labels = map[string]string{}
+	}
+	image.Annotations = map[string]string{}
+	for _, label := range imageLabels(dist, image.Dockerfile, opts.variant.buildArgs) {
+		key := "org.opencontainers.image." + label.key
+		image.Labels[key] = label.value
+		if indexAnnotation(label) {
+			image.Annotations[key] = label.value
+		}
+	}
+
+	images := []config.DockerV2{image}
+	if dockersV2ArchTags {
+		for _, platform := range platforms {
+			archImage := image
+			archImage.Platforms = []string{platform}
+			archImage.Tags = nil
+			archTag := platformImageTag(platform, opts)
+			archImage.ID = image.ID + "-" + archTag
+			for _, tag := range imageTags() {
+				archImage.Tags = append(archImage.Tags, fmt.Sprintf("%s-%s", tag, archTag))
+			}
+			images = append(images, archImage)
+		}
+	}
+	return images
+}
+
+// platformImageTag returns the per-arch tag suffix of a linux platform, e.g.
+// armv7 for linux/arm/v7.
+func platformImageTag(platform string, opts containerImageOptions) string {
+	parts := strings.Split(platform, "/")
+	osArch := osArchInfo{os: parts[0], arch: parts[1], variant: opts.variant.name}
+	if len(parts) > 2 {
+		osArch.version = strings.TrimPrefix(parts[2], "v")
+	}
+	return osArch.imageTag()
+}
@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWindowsImageHosts(t *testing.T) {
+	chdirRoot(t)
+
+	project, err := BuildDistribution(contribDistro, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := placeholderVars().with(map[string]any{"Env": map[string]string{}})
+
+	// built returns the Windows Server hosts building or pushing an item.
+	built := func(t *testing.T, skip string) []string {
+		t.Helper()
+		var hosts []string
+		for _, r := range releaseRuntimes() {
+			skipped, err := vars.withRuntime(r).skipped(skip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !skipped {
+				if r.goos != "windows" {
+					t.Fatalf("%q isn't skipped on %s", skip, r.goos)
+				}
+				hosts = append(hosts, r.winHost)
+			}
+		}
+		return hosts
+	}
+
+	var windowsImages int
+	for _, docker := range project.Dockers {
+		if docker.Goos != "windows" {
+			continue
+		}
+		windowsImages++
+		want := "2022"
+		if strings.Contains(docker.ImageTemplates[0], "-2025-") {
+			want = "2025"
+		}
+		for _, skip := range []string{docker.SkipBuild, docker.SkipPush} {
+			if hosts := built(t, skip); len(hosts) != 1 || hosts[0] != want {
+				t.Errorf("%s is built on %v, want %s", docker.ImageTemplates[0], hosts, want)
+			}
+		}
+	}
+	if windowsImages == 0 {
+		t.Fatal("no windows images")
+	}
+
+	var windowsManifests int
+	for _, manifest := range project.DockerManifests {
+		if !strings.HasSuffix(manifest.NameTemplate, "-windows-servercore") {
+			continue
+		}
+		windowsManifests++
+		if hosts := built(t, manifest.SkipPush); len(hosts) != 1 || hosts[0] != "2025" {
+			t.Errorf("%s is pushed on %v, want the 2025 host, which runs last", manifest.NameTemplate, hosts)
+		}
+	}
+
+	if windowsManifests == 0 {
+		t.Fatal("no windows manifest lists")
+	}
+
+	// Only the default host uploads the windows archives and packages.
+	for _, r := range releaseRuntimes() {
+		skipped, err := vars.withRuntime(r).skipped(project.Release.SkipUpload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := r.winHost != "" && r.winHost != defaultWinHost; skipped != want {
+			t.Errorf("release upload skipped on %s %s = %v, want %v", r.goos, r.winHost, skipped, want)
+		}
+	}
+}
+
+func TestFloatingTagManifests(t *testing.T) {
+	chdirRoot(t)
+
+	project, err := BuildDistribution(coreDistro, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versionImages := map[string][]string{}
+	for _, manifest := range project.DockerManifests {
+		name, ok := strings.CutSuffix(manifest.NameTemplate, ":{{ .Version }}")
+		if ok && slices.ContainsFunc(manifest.ImageTemplates, func(image string) bool { return strings.Contains(image, "-windows-") }) {
+			versionImages[name] = manifest.ImageTemplates
+		}
+	}
+	if len(versionImages) == 0 {
+		t.Fatal("no multi-OS manifest lists")
+	}
+
+	var floating int
+	for _, manifest := range project.DockerManifests {
+		name, ok := strings.CutSuffix(manifest.NameTemplate, ":"+floatingTag)
+		if !ok {
+			continue
+		}
+		floating++
+		if want := versionImages[name]; !slices.Equal(manifest.ImageTemplates, want) {
+			t.Errorf("%s lists %v, want the version tag's %v", manifest.NameTemplate, manifest.ImageTemplates, want)
+		}
+
+		for _, r := range releaseRuntimes() {
+			for _, nightly := range []bool{false, true} {
+				vars := placeholderVars().with(map[string]any{"Env": map[string]string{}, "IsNightly": nightly})
+				skipped, err := vars.withRuntime(r).skipped(manifest.SkipPush)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := r.goos == "windows" || nightly; skipped != want {
+					t.Errorf("%s push skipped on %s %s, nightly %v = %v, want %v", manifest.NameTemplate, r.goos, r.winHost, nightly, skipped, want)
+				}
+			}
+		}
+	}
+	if floating != len(versionImages) {
+		t.Errorf("%d floating manifest lists, want %d", floating, len(versionImages))
+	}
+}
@@ -44,13 +44,23 @@ var (
 		}
 		d.ContainerImages = slices.Concat(
 			newContainerImages(d.Name, "linux", baseArchs, containerImageOptions{armVersion: "7"}),
-			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersion: "2019"}),
-			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersion: "2022"}),
+			newContainerImages(d.Name, "linux", baseArchs, containerImageOptions{armVersion: "7", variant: debugVariant}),
+			newContainerImages(d.Name, "linux", baseArchs, containerImageOptions{armVersion: "7", variant: distrolessVariant}),
+			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersions: winContainerVersions}),
+			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersions: winContainerVersions, winBase: winBaseServercore}),
 		)
 		d.ContainerImageManifests = slices.Concat(
-			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{}),
+			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{floatingTags: true, winVersions: winContainerVersions}),
+			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{variant: debugVariant, floatingTags: true}),
+			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{variant: distrolessVariant, floatingTags: true}),
+			newContainerImageManifests(d.Name, "windows", winContainerArchs, containerImageOptions{winVersions: winContainerVersions, winBase: winBaseServercore}),
 		)
-	}).withPackagingDefaults().withDefaultConfigIncluded().withVarLibDir("otelcol-contrib", "otelcol-contrib").build()
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, baseArchs, containerImageOptions{floatingTags: true}),
+			newContainerImagesV2(d.Name, baseArchs, containerImageOptions{variant: debugVariant, floatingTags: true}),
+			newContainerImagesV2(d.Name, baseArchs, containerImageOptions{variant: distrolessVariant, floatingTags: true}),
+		)
+	}).withPackagingDefaults().withDefaultConfigIncluded().withVarLibDir("otelcol-contrib", "otelcol-contrib")
 
 	// contrib build-only project
 	contribBuildOnlyDist = newDistributionBuilder(contribDistro).withConfigFunc(func(d *distribution) {
@@ -65,6 +75,39 @@ var (
 		withDefaultEnv().
 		withDefaultPartial().
 		withDefaultRelease().
+		withNightlyConfig()
+
+	// contrib FIPS 140-3 distro, built and packaged in a single project
+	contribFIPSDist = newDistributionBuilder(contribDistro+fipsSuffix).withConfigFunc(func(d *distribution) {
+		d.BuildConfigs = []buildConfig{
+			&fullBuildConfig{TargetOS: "linux", TargetArch: fipsArchs, BuildDir: defaultBuildDir},
+		}
+		d.ContainerImages = slices.Concat(
+			newContainerImages(d.Name, "linux", fipsArchs, containerImageOptions{}),
+		)
+		d.ContainerImageManifests = slices.Concat(
+			newContainerImageManifests(d.Name, "linux", fipsArchs, containerImageOptions{floatingTags: true}),
+		)
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, fipsArchs, containerImageOptions{floatingTags: true}),
+		)
+	}).withDefaultArchives().
+		withDefaultNfpms().
+		withDefaultChecksum().
+		withDefaultSigns().
+		withDefaultDockerSigns().
+		withDefaultSBOMs().
+		withProvenance().
+		withImageSBOMs(imageSBOMFormats...).
+		withDefaultMonorepo().
+		withDefaultEnv().
+		withDefaultPartial().
+		withDefaultRelease().
+		withComponentSBOM().
+		withInventory().
 		withNightlyConfig().
-		build()
+		withDefaultSnapshot().
+		withDefaultConfigIncluded().
+		withVarLibDir("otelcol-contrib", "otelcol-contrib").
+		withFIPS()
 )
@@ -3,12 +3,46 @@
 
 package internal
 
-import "slices"
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+)
 
 var (
 	ebpfProfilerArchs = []string{"amd64", "arm64"}
+	// ebpfProfilerCapabilities are set on the binary of the rootless image.
+	ebpfProfilerCapabilities = []string{"BPF", "PERFMON", "SYS_PTRACE"}
+
+	// rootlessVariant runs the profiler as a non-root user with file capabilities.
+	rootlessVariant = imageVariant{
+		name:       "rootless",
+		dockerfile: "Rootless.dockerfile",
+		buildArgs:  map[string]string{"CAPABILITIES": setcapCapabilities(ebpfProfilerCapabilities)},
+		labels:     map[string]string{"io.opentelemetry.collector.capabilities": capabilityList(ebpfProfilerCapabilities)},
+	}
 )
 
+// setcapCapabilities formats capabilities for setcap, e.g. cap_bpf,cap_perfmon.
+func setcapCapabilities(capabilities []string) string {
+	var caps []string
+	for _, capability := range capabilities {
+		caps = append(caps, "cap_"+strings.ToLower(capability))
+	}
+	return strings.Join(caps, ",")
+}
+
+// capabilityList formats capabilities for labels, e.g. CAP_BPF,CAP_PERFMON.
+func capabilityList(capabilities []string) string {
+	var caps []string
+	for _, capability := range capabilities {
+		caps = append(caps, "CAP_"+capability)
+	}
+	return strings.Join(caps, ",")
+}
+
 var (
 	// ebpf-profiler distro
 	ebpfProfilerDist = newDistributionBuilder(ebpfProfilerDistro).withConfigFunc(func(d *distribution) {
@@ -17,11 +51,21 @@ var (
 		}
 		d.ContainerImages = slices.Concat(
 			newContainerImages(d.Name, "linux", ebpfProfilerArchs, containerImageOptions{}),
+			newContainerImages(d.Name, "linux", ebpfProfilerArchs, containerImageOptions{variant: rootlessVariant}),
 		)
 		d.ContainerImageManifests = slices.Concat(
-			newContainerImageManifests(d.Name, "linux", ebpfProfilerArchs, containerImageOptions{}),
+			newContainerImageManifests(d.Name, "linux", ebpfProfilerArchs, containerImageOptions{floatingTags: true}),
+			newContainerImageManifests(d.Name, "linux", ebpfProfilerArchs, containerImageOptions{variant: rootlessVariant, floatingTags: true}),
+		)
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, ebpfProfilerArchs, containerImageOptions{floatingTags: true}),
+			newContainerImagesV2(d.Name, ebpfProfilerArchs, containerImageOptions{variant: rootlessVariant, floatingTags: true}),
+		)
+		d.Env = append(d.Env,
+			"TARGET_ARCH={{ .Runtime.Goarch }}",
+			fmt.Sprintf("EBPF_PROFILER_ROOTLESS_IMAGE=%s/%s", imageRepositories[0], imageName(d.Name, containerImageOptions{})),
+			fmt.Sprintf(`EBPF_PROFILER_CAPABILITIES=["%s"]`, strings.Join(ebpfProfilerCapabilities, `", "`)),
 		)
-		d.Env = append(d.Env, "TARGET_ARCH={{ .Runtime.Goarch }}")
 		d.LdFlags = "-extldflags=-static"
 		d.GoTags = "osusergo,netgo"
 	}).withDefaultArchives().
@@ -29,11 +73,21 @@ var (
 		withDefaultSigns().
 		withDefaultDockerSigns().
 		withDefaultSBOMs().
+		withProvenance().
+		withImageSBOMs(imageSBOMFormats...).
 		withDefaultMonorepo().
 		withDefaultEnv().
 		withDefaultPartial().
 		withDefaultRelease().
+		withComponentSBOM().
+		withInventory().
 		withNightlyConfig().
 		withDefaultSnapshot().
-		build()
+		withConfigFunc(func(d *distribution) {
+			// Publish the Kubernetes settings the rootless image needs with the release.
+			d.Release.TemplatedExtraFiles = append(d.Release.TemplatedExtraFiles, config.TemplatedExtraFile{
+				Source:      "rootless-security-context.yaml.tmpl",
+				Destination: d.Name + "_{{ .Version }}_rootless-security-context.yaml",
+			})
+		})
 )
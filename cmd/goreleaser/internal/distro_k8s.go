@@ -14,22 +14,64 @@ var (
 		}
 		d.ContainerImages = slices.Concat(
 			newContainerImages(d.Name, "linux", k8sArchs, containerImageOptions{armVersion: "7"}),
-			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersion: "2019"}),
-			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersion: "2022"}),
+			newContainerImages(d.Name, "linux", k8sArchs, containerImageOptions{armVersion: "7", variant: debugVariant}),
+			newContainerImages(d.Name, "linux", k8sArchs, containerImageOptions{armVersion: "7", variant: distrolessVariant}),
+			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersions: winContainerVersions}),
 		)
 		d.ContainerImageManifests = slices.Concat(
-			newContainerImageManifests(d.Name, "linux", k8sArchs, containerImageOptions{}),
+			newContainerImageManifests(d.Name, "linux", k8sArchs, containerImageOptions{floatingTags: true, winVersions: winContainerVersions}),
+			newContainerImageManifests(d.Name, "linux", k8sArchs, containerImageOptions{variant: debugVariant, floatingTags: true}),
+			newContainerImageManifests(d.Name, "linux", k8sArchs, containerImageOptions{variant: distrolessVariant, floatingTags: true}),
+		)
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, k8sArchs, containerImageOptions{floatingTags: true}),
+			newContainerImagesV2(d.Name, k8sArchs, containerImageOptions{variant: debugVariant, floatingTags: true}),
+			newContainerImagesV2(d.Name, k8sArchs, containerImageOptions{variant: distrolessVariant, floatingTags: true}),
+		)
+	}).withDefaultArchives().
+		withDefaultChecksum().
+		withDefaultSigns().
+		withDefaultDockerSigns().
+		withDefaultSBOMs().
+		withProvenance().
+		withImageSBOMs(imageSBOMFormats...).
+		withDefaultMonorepo().
+		withDefaultEnv().
+		withDefaultPartial().
+		withDefaultRelease().
+		withComponentSBOM().
+		withInventory().
+		withNightlyConfig().
+		withDefaultSnapshot()
+
+	// k8s FIPS 140-3 distro
+	k8sFIPSDist = newDistributionBuilder(k8sDistro + fipsSuffix).withConfigFunc(func(d *distribution) {
+		d.BuildConfigs = []buildConfig{
+			&fullBuildConfig{TargetOS: "linux", TargetArch: fipsArchs, BuildDir: defaultBuildDir},
+		}
+		d.ContainerImages = slices.Concat(
+			newContainerImages(d.Name, "linux", fipsArchs, containerImageOptions{}),
+		)
+		d.ContainerImageManifests = slices.Concat(
+			newContainerImageManifests(d.Name, "linux", fipsArchs, containerImageOptions{floatingTags: true}),
+		)
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, fipsArchs, containerImageOptions{floatingTags: true}),
 		)
 	}).withDefaultArchives().
 		withDefaultChecksum().
 		withDefaultSigns().
 		withDefaultDockerSigns().
 		withDefaultSBOMs().
+		withProvenance().
+		withImageSBOMs(imageSBOMFormats...).
 		withDefaultMonorepo().
 		withDefaultEnv().
 		withDefaultPartial().
 		withDefaultRelease().
+		withComponentSBOM().
+		withInventory().
 		withNightlyConfig().
 		withDefaultSnapshot().
-		build()
+		withFIPS()
 )
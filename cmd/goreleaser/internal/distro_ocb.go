@@ -23,10 +23,13 @@ var (
 		d.ContainerImageManifests = slices.Concat(
 			newContainerImageManifests(d.Name, "linux", ocbArchs, containerImageOptions{binaryRelease: true}),
 		)
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, ocbArchs, containerImageOptions{binaryRelease: true}),
+		)
 		d.LdFlags = "-s -w -X go.opentelemetry.io/collector/cmd/builder/internal.version={{ .Version }}"
 	}).withBinaryPackagingDefaults().
 		withBinaryMonorepo(".core/cmd/builder").
 		withDefaultBinaryRelease(ocbReleaseHeader).
-		withNightlyConfig().
-		build()
+		withInventory().
+		withNightlyConfig()
 )
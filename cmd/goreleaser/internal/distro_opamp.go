@@ -4,6 +4,7 @@
 package internal
 
 import (
+	"fmt"
 	"path"
 	"slices"
 
@@ -28,12 +29,17 @@ var (
 		d.ContainerImageManifests = slices.Concat(
 			newContainerImageManifests(d.Name, "linux", opAmpArchs, containerImageOptions{binaryRelease: true}),
 		)
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, opAmpArchs, containerImageOptions{binaryRelease: true}),
+		)
 		d.LdFlags = "-s -w -X github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/internal.version={{ .Version }}"
 	}).withBinaryPackagingDefaults().
 		withBinaryMonorepo(".contrib/cmd/opampsupervisor").
 		withDefaultBinaryRelease(opampReleaseHeader).
+		withInventory().
 		withDefaultNfpms().
 		withDefaultMSIConfig().
+		withDefaultWindowsPackageManagers().
 		// This is required because of some non-obvious path/workdir handling in
 		// Github Actions specific to the binaries CI.
 		withConfigFunc(func(d *distribution) {
@@ -57,7 +63,21 @@ var (
 
 			d.MsiConfig[0].Files = append(d.MsiConfig[0].Files, "config.windows.example.yaml")
 			d.MsiConfig[0].WXS = path.Join("cmd", d.Name, d.MsiConfig[0].WXS)
+
+			// Homebrew installs the example configuration as the default one,
+			// from archives shipping it next to the binary.
+			d.Archives = append(d.Archives, config.Archive{
+				ID:           d.Name + "-brew",
+				NameTemplate: "{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}",
+				IDs:          []string{d.Name + "-linux", d.Name + "-darwin"},
+				Files: []config.File{
+					{Source: path.Join("cmd", d.Name, "config.example.yaml"), StripParent: true},
+				},
+			})
+			for i := range d.Brews {
+				d.Brews[i].IDs = []string{d.Name + "-brew"}
+				d.Brews[i].ExtraInstall = fmt.Sprintf(`(etc/"%s").install "config.example.yaml" => "config.yaml"`, d.Name)
+			}
 		}).
-		withNightlyConfig().
-		build()
+		withNightlyConfig()
 )
@@ -16,11 +16,53 @@ var (
 		}
 		d.ContainerImages = slices.Concat(
 			newContainerImages(d.Name, "linux", baseArchs, containerImageOptions{armVersion: "7"}),
-			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersion: "2019"}),
-			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersion: "2022"}),
+			newContainerImages(d.Name, "linux", baseArchs, containerImageOptions{armVersion: "7", variant: debugVariant}),
+			newContainerImages(d.Name, "linux", baseArchs, containerImageOptions{armVersion: "7", variant: distrolessVariant}),
+			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersions: winContainerVersions}),
 		)
 		d.ContainerImageManifests = slices.Concat(
-			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{}),
+			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{floatingTags: true, winVersions: winContainerVersions}),
+			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{variant: debugVariant, floatingTags: true}),
+			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{variant: distrolessVariant, floatingTags: true}),
 		)
-	}).withPackagingDefaults().withDefaultConfigIncluded().withVarLibDir("otel", "otel").build()
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, baseArchs, containerImageOptions{floatingTags: true}),
+			newContainerImagesV2(d.Name, baseArchs, containerImageOptions{variant: debugVariant, floatingTags: true}),
+			newContainerImagesV2(d.Name, baseArchs, containerImageOptions{variant: distrolessVariant, floatingTags: true}),
+		)
+	}).withPackagingDefaults().withDefaultConfigIncluded().withVarLibDir("otel", "otel")
+
+	// otelcol (core) FIPS 140-3 distro
+	otelColFIPSDist = newDistributionBuilder(coreDistro+fipsSuffix).withConfigFunc(func(d *distribution) {
+		d.BuildConfigs = []buildConfig{
+			&fullBuildConfig{TargetOS: "linux", TargetArch: fipsArchs, BuildDir: defaultBuildDir},
+		}
+		d.ContainerImages = slices.Concat(
+			newContainerImages(d.Name, "linux", fipsArchs, containerImageOptions{}),
+		)
+		d.ContainerImageManifests = slices.Concat(
+			newContainerImageManifests(d.Name, "linux", fipsArchs, containerImageOptions{floatingTags: true}),
+		)
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, fipsArchs, containerImageOptions{floatingTags: true}),
+		)
+	}).withDefaultArchives().
+		withDefaultNfpms().
+		withDefaultChecksum().
+		withDefaultSigns().
+		withDefaultDockerSigns().
+		withDefaultSBOMs().
+		withProvenance().
+		withImageSBOMs(imageSBOMFormats...).
+		withDefaultMonorepo().
+		withDefaultEnv().
+		withDefaultPartial().
+		withDefaultRelease().
+		withComponentSBOM().
+		withInventory().
+		withNightlyConfig().
+		withDefaultSnapshot().
+		withDefaultConfigIncluded().
+		withVarLibDir("otel", "otel").
+		withFIPS()
 )
@@ -16,11 +16,19 @@ var (
 		}
 		d.ContainerImages = slices.Concat(
 			newContainerImages(d.Name, "linux", baseArchs, containerImageOptions{armVersion: "7"}),
-			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersion: "2019"}),
-			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersion: "2022"}),
+			newContainerImages(d.Name, "linux", baseArchs, containerImageOptions{armVersion: "7", variant: debugVariant}),
+			newContainerImages(d.Name, "linux", baseArchs, containerImageOptions{armVersion: "7", variant: distrolessVariant}),
+			newContainerImages(d.Name, "windows", winContainerArchs, containerImageOptions{winVersions: winContainerVersions}),
 		)
 		d.ContainerImageManifests = slices.Concat(
-			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{}),
+			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{floatingTags: true, winVersions: winContainerVersions}),
+			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{variant: debugVariant, floatingTags: true}),
+			newContainerImageManifests(d.Name, "linux", baseArchs, containerImageOptions{variant: distrolessVariant, floatingTags: true}),
 		)
-	}).withPackagingDefaults().withVarLibDir("otelcol-otlp", "otelcol-otlp").build()
+		d.ContainerImagesV2 = slices.Concat(
+			newContainerImagesV2(d.Name, baseArchs, containerImageOptions{floatingTags: true}),
+			newContainerImagesV2(d.Name, baseArchs, containerImageOptions{variant: debugVariant, floatingTags: true}),
+			newContainerImagesV2(d.Name, baseArchs, containerImageOptions{variant: distrolessVariant, floatingTags: true}),
+		)
+	}).withPackagingDefaults().withVarLibDir("otelcol-otlp", "otelcol-otlp")
 )
@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFIPSBuilds(t *testing.T) {
+	chdirRoot(t)
+
+	for _, dist := range []string{coreDistro, contribDistro, k8sDistro} {
+		t.Run(dist, func(t *testing.T) {
+			project, err := BuildFIPSDistribution(dist)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Contains(project.Env, "GOFIPS140="+fipsModuleVersion) {
+				t.Errorf("env = %v, want GOFIPS140=%s", project.Env, fipsModuleVersion)
+			}
+			if len(project.Builds) == 0 {
+				t.Fatal("no builds")
+			}
+			for _, build := range project.Builds {
+				if !slices.Equal(build.Tags, []string{fipsBuildTag}) {
+					t.Errorf("build %s tags = %v, want [%s]", build.ID, build.Tags, fipsBuildTag)
+				}
+			}
+
+			// Building twice mustn't stack the tag on the shared builder.
+			project, err = BuildFIPSDistribution(dist)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tags := project.Builds[0].Tags; !slices.Equal(tags, []string{fipsBuildTag}) {
+				t.Errorf("rebuilt tags = %v, want [%s]", tags, fipsBuildTag)
+			}
+		})
+	}
+
+	project, err := BuildDistribution(coreDistro, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, build := range project.Builds {
+		if len(build.Tags) != 0 {
+			t.Errorf("regular build %s tags = %v", build.ID, build.Tags)
+		}
+	}
+	if slices.Contains(project.Env, "GOFIPS140="+fipsModuleVersion) {
+		t.Errorf("regular env = %v, has GOFIPS140", project.Env)
+	}
+}
@@ -4,33 +4,60 @@
 package internal
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
 )
 
-func BuildDistribution(dist string, onlyBuild bool) config.Project {
+// BuildDistribution returns the goreleaser configuration of a distribution or
+// binary.
+func BuildDistribution(dist string, onlyBuild bool) (config.Project, error) {
+	var b *distributionBuilder
 	switch dist {
 	case coreDistro:
-		return otelColDist.buildProject()
+		b = otelColDist
 	case otlpDistro:
-		return otlpDist.buildProject()
+		b = otlpDist
 	case k8sDistro:
-		return k8sDist.buildProject()
+		b = k8sDist
 	case ebpfProfilerDistro:
-		return ebpfProfilerDist.buildProject()
+		b = ebpfProfilerDist
 	case contribDistro:
+		b = contribDist
 		if onlyBuild {
-			return contribBuildOnlyDist.buildProject()
+			b = contribBuildOnlyDist
 		}
-		return contribDist.buildProject()
 	case ocbBinary:
-		return ocbDist.buildProject()
+		b = ocbDist
 	case opampBinary:
-		return opampDist.buildProject()
+		b = opampDist
 	default:
-		panic("Unknown distribution")
+		return config.Project{}, fmt.Errorf("unknown distribution %q", dist)
 	}
+	if err := loadSourceMetadata(dist); err != nil {
+		return config.Project{}, err
+	}
+	return b.build().buildProject(), nil
+}
+
+// BuildFIPSDistribution returns the FIPS 140-3 flavour of a distribution.
+func BuildFIPSDistribution(dist string) (config.Project, error) {
+	var b *distributionBuilder
+	switch dist {
+	case coreDistro:
+		b = otelColFIPSDist
+	case contribDistro:
+		b = contribFIPSDist
+	case k8sDistro:
+		b = k8sFIPSDist
+	default:
+		return config.Project{}, fmt.Errorf("unknown FIPS distribution %q", dist)
+	}
+	if err := loadSourceMetadata(dist); err != nil {
+		return config.Project{}, err
+	}
+	return b.build().buildProject(), nil
 }
 
 func armVersions(dist string) []string {
@@ -42,8 +69,34 @@ func armVersions(dist string) []string {
 
 // imageName translates a distribution name to a container image name.
 func imageName(dist string, opts containerImageOptions) string {
+	name := strings.Replace(dist, binaryNamePrefix, imageNamePrefix, 1)
 	if opts.binaryRelease {
-		return imageNamePrefix + "-" + dist
+		name = imageNamePrefix + "-" + dist
+	}
+	if imageNameTemplate == nil {
+		return name
+	}
+
+	var sb strings.Builder
+	if err := imageNameTemplate.Execute(&sb, imageNameData{Distribution: dist, Name: name}); err != nil {
+		panic(err)
+	}
+	return sb.String()
+}
+
+// msiServiceArgsProperty returns the MSI property holding the service arguments.
+func msiServiceArgsProperty(dist string) string {
+	if dist == opampBinary {
+		return "SUPERVISOR_SVC_ARGS"
+	}
+	return "COLLECTOR_SVC_ARGS"
+}
+
+// inventoryFile returns the name of the inventory of a distribution, next to
+// its goreleaser configuration.
+func inventoryFile(dist string) string {
+	if strings.HasSuffix(dist, fipsSuffix) {
+		return "inventory" + fipsSuffix + ".json"
 	}
-	return strings.Replace(dist, binaryNamePrefix, imageNamePrefix, 1)
+	return "inventory.json"
 }
@@ -0,0 +1,435 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+)
+
+const (
+	// defaultArm is the goarm goreleaser builds when none is configured.
+	defaultArm = "6"
+	// archiveNameTemplate, binaryNameTemplate and packageNameTemplate are the
+	// goreleaser defaults naming archives, binaries and nfpm packages.
+	archiveNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ with .Arm }}v{{ . }}{{ end }}{{ with .Mips }}_{{ . }}{{ end }}"
+	binaryNameTemplate  = "{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ with .Arm }}v{{ . }}{{ end }}{{ with .Mips }}_{{ . }}{{ end }}"
+	packageNameTemplate = "{{ .PackageName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ with .Arm }}v{{ . }}{{ end }}{{ with .Mips }}_{{ . }}{{ end }}"
+	// sbomNameTemplate is the goreleaser default name of archive and package SBOMs.
+	sbomNameTemplate = "{{ .ArtifactName }}.sbom.json"
+)
+
+// Inventory lists the artifacts and images a release of a distribution
+// publishes. Names are rendered from the goreleaser configuration.
+type Inventory struct {
+	Distribution string     `json:"distribution"`
+	Version      string     `json:"version"`
+	Platforms    []Platform `json:"platforms"`
+	// Manifests are the multi-platform image manifest lists.
+	Manifests []string `json:"manifests,omitempty"`
+	// Files are published with the release independently of the platforms.
+	Files []string `json:"files,omitempty"`
+}
+
+// Platform lists the artifacts and images of a build target.
+type Platform struct {
+	OS        string     `json:"os"`
+	Arch      string     `json:"arch"`
+	Arm       string     `json:"arm,omitempty"`
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	Images    []string   `json:"images,omitempty"`
+}
+
+// Artifact is a file published with the release and the files verifying it.
+type Artifact struct {
+	// Type is archive, binary, package, msi or pkg.
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Checksum string `json:"checksum,omitempty"`
+	SBOM     string `json:"sbom,omitempty"`
+	// SBOMChecksum is the checksum of the SBOM, which goreleaser checksums
+	// like the artifacts.
+	SBOMChecksum string `json:"sbom_checksum,omitempty"`
+	// Signatures sign the artifact, its checksum, its SBOM and the SBOM checksum.
+	Signatures []string `json:"signatures,omitempty"`
+}
+
+// target is a build target of the project.
+type target struct {
+	build         string
+	binary        string
+	os, arch, arm string
+}
+
+func (t target) vars(v templateVars) templateVars {
+	amd64 := ""
+	if t.arch == "amd64" {
+		amd64 = "v1"
+	}
+	return v.with(map[string]any{
+		"Binary": t.binary,
+		"Os":     t.os,
+		"Arch":   t.arch,
+		"Arm":    t.arm,
+		"Mips":   "",
+		"Amd64":  amd64,
+	})
+}
+
+// NewInventory returns the inventory of a goreleaser project. It keeps the
+// release values as templates, e.g. {{ .Version }}, see RenderInventory.
+func NewInventory(project config.Project) (Inventory, error) {
+	return newInventory(project, placeholderVars())
+}
+
+// RenderInventory returns the inventory of a goreleaser project with the names
+// rendered for the given release.
+func RenderInventory(project config.Project, opts RenderOptions) (Inventory, error) {
+	vars, err := releaseVars(project, opts)
+	if err != nil {
+		return Inventory{}, err
+	}
+	return newInventory(project, vars)
+}
+
+func newInventory(project config.Project, release templateVars) (Inventory, error) {
+	vars, err := release.with(map[string]any{"ProjectName": project.ProjectName}).withRuntime(releaseRuntimes()[0]).withEnv(project.Env)
+	if err != nil {
+		return Inventory{}, err
+	}
+	inv := Inventory{Distribution: projectDistribution(project), Version: fmt.Sprint(vars["Version"])}
+	platforms := map[string]*Platform{}
+	platform := func(t target) *Platform {
+		key := t.os + "/" + t.arch + "/" + t.arm
+		if p, ok := platforms[key]; ok {
+			return p
+		}
+		p := &Platform{OS: t.os, Arch: t.arch, Arm: t.arm}
+		platforms[key] = p
+		return p
+	}
+
+	targets := projectTargets(project)
+	artifacts, err := newInventoryArtifacts(project, targets, vars)
+	if err != nil {
+		return Inventory{}, err
+	}
+	for _, a := range artifacts {
+		p := platform(a.target)
+		p.Artifacts = append(p.Artifacts, a.Artifact)
+	}
+
+	images, manifests, err := inventoryImages(project, vars)
+	if err != nil {
+		return Inventory{}, err
+	}
+	for _, image := range images {
+		p := platform(image.target)
+		p.Images = append(p.Images, image.name)
+	}
+	// Multi-OS manifest lists replace the linux ones of the same name.
+	for _, manifest := range manifests {
+		if !slices.Contains(inv.Manifests, manifest) {
+			inv.Manifests = append(inv.Manifests, manifest)
+		}
+	}
+
+	for _, f := range project.Release.ExtraFiles {
+		name, err := vars.render(f.NameTemplate)
+		if err != nil {
+			return Inventory{}, err
+		}
+		inv.Files = append(inv.Files, name)
+	}
+	for _, f := range project.Release.TemplatedExtraFiles {
+		name, err := vars.render(f.Destination)
+		if err != nil {
+			return Inventory{}, err
+		}
+		inv.Files = append(inv.Files, name)
+	}
+
+	for _, p := range platforms {
+		inv.Platforms = append(inv.Platforms, *p)
+	}
+	slices.SortFunc(inv.Platforms, func(a, b Platform) int {
+		return cmp.Or(cmp.Compare(a.OS, b.OS), cmp.Compare(a.Arch, b.Arch), cmp.Compare(a.Arm, b.Arm))
+	})
+	return inv, nil
+}
+
+// projectDistribution returns the distribution of a project from its build
+// IDs, named <distribution>-<os>.
+func projectDistribution(project config.Project) string {
+	for _, build := range project.Builds {
+		if len(build.Goos) > 0 {
+			return strings.TrimSuffix(build.ID, "-"+build.Goos[0])
+		}
+	}
+	return project.ProjectName
+}
+
+// projectTargets returns the targets of every build.
+func projectTargets(project config.Project) []target {
+	var targets []target
+	for _, build := range project.Builds {
+		for _, goos := range build.Goos {
+			for _, goarch := range build.Goarch {
+				t := target{build: build.ID, binary: build.Binary, os: goos, arch: goarch}
+				if goarch != armArchitecture {
+					targets = append(targets, t)
+					continue
+				}
+				goarms := build.Goarm
+				if len(goarms) == 0 {
+					goarms = []string{defaultArm}
+				}
+				for _, goarm := range goarms {
+					t.arm = goarm
+					targets = append(targets, t)
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// filterTargets returns the targets of the given builds, all of them without IDs.
+func filterTargets(targets []target, ids []string, goos ...string) []target {
+	var filtered []target
+	for _, t := range targets {
+		if len(ids) > 0 && !slices.Contains(ids, t.build) {
+			continue
+		}
+		if len(goos) > 0 && !slices.Contains(goos, t.os) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	return filtered
+}
+
+type inventoryArtifact struct {
+	Artifact
+	target target
+}
+
+func newInventoryArtifacts(project config.Project, targets []target, vars templateVars) ([]inventoryArtifact, error) {
+	var artifacts []inventoryArtifact
+	add := func(typ string, t target, nameTemplate, extension string, values map[string]any) error {
+		name, err := t.vars(vars).with(values).render(nameTemplate)
+		if err != nil {
+			return fmt.Errorf("%s name: %w", typ, err)
+		}
+		artifacts = append(artifacts, inventoryArtifact{Artifact: Artifact{Type: typ, Name: name + extension}, target: t})
+		return nil
+	}
+
+	for _, archive := range project.Archives {
+		formats := archive.Formats
+		if len(formats) == 0 {
+			formats = []string{"tar.gz"}
+		}
+		for _, t := range filterTargets(targets, archive.IDs) {
+			for _, format := range formats {
+				if format == "binary" {
+					extension := ""
+					if t.os == "windows" {
+						extension = ".exe"
+					}
+					if err := add("binary", t, cmp.Or(archive.NameTemplate, binaryNameTemplate), extension, nil); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				if err := add("archive", t, cmp.Or(archive.NameTemplate, archiveNameTemplate), "."+format, nil); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for _, nfpm := range project.NFPMs {
+		for _, t := range filterTargets(targets, nfpm.IDs, "linux", "aix") {
+			for _, format := range nfpm.Formats {
+				values := map[string]any{"PackageName": nfpm.PackageName}
+				if err := add("package", t, packageNameTemplate, "."+format, values); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for _, msi := range project.MSI {
+		for _, t := range filterTargets(targets, msi.IDs, "windows") {
+			if err := add("msi", t, msi.Name, ".msi", map[string]any{"MsiArch": msiArch(t.arch)}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, pkg := range project.Pkgs {
+		for _, t := range filterTargets(targets, pkg.IDs, "darwin") {
+			published, err := publishedOnRuntime(t.vars(vars), pkg.If, true)
+			if err != nil {
+				return nil, fmt.Errorf("pkg: %w", err)
+			}
+			if !published {
+				continue
+			}
+			if err := add("pkg", t, pkg.Name, ".pkg", nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i, a := range artifacts {
+		if project.Checksum.Split {
+			// Split checksums are named after the artifact and the algorithm, sha256 by default.
+			a.Checksum = a.Name + "." + cmp.Or(project.Checksum.Algorithm, "sha256")
+		}
+		for _, sbom := range project.SBOMs {
+			if signArtifactsMatch(sbom.Artifacts, a.Type) {
+				name, err := a.target.vars(vars).with(map[string]any{"ArtifactName": a.Name}).render(sbomNameTemplate)
+				if err != nil {
+					return nil, err
+				}
+				a.SBOM = name
+			}
+		}
+		if a.SBOM != "" && a.Checksum != "" {
+			a.SBOMChecksum = a.SBOM + "." + cmp.Or(project.Checksum.Algorithm, "sha256")
+		}
+		for _, sign := range project.Signs {
+			for _, file := range []struct{ name, typ string }{
+				{a.Name, a.Type},
+				{a.Checksum, "checksum"},
+				{a.SBOM, "sbom"},
+				{a.SBOMChecksum, "checksum"},
+			} {
+				if file.name == "" || !signArtifactsMatch(sign.Artifacts, file.typ) {
+					continue
+				}
+				a.Signatures = append(a.Signatures, strings.ReplaceAll(cmp.Or(sign.Signature, "${artifact}.sig"), "${artifact}", file.name))
+			}
+		}
+		artifacts[i] = a
+	}
+	return artifacts, nil
+}
+
+// signArtifactsMatch reports whether the artifacts setting of a sign or SBOM
+// selects an artifact type. As in goreleaser, msi and pkg are installers, which
+// package doesn't select.
+func signArtifactsMatch(artifacts, typ string) bool {
+	switch artifacts {
+	case "all", "any":
+		return true
+	case "installer":
+		return typ == "msi" || typ == "pkg"
+	}
+	return artifacts == typ
+}
+
+// msiArch returns the .MsiArch of a goarch.
+func msiArch(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x64"
+	case "386":
+		return "x86"
+	}
+	return goarch
+}
+
+// publishedOnRuntime reports whether an item is published by any release
+// runtime, given its skip template or, with isIf, its if template.
+func publishedOnRuntime(vars templateVars, condition string, isIf bool) (bool, error) {
+	for _, r := range releaseRuntimes() {
+		skipped, err := vars.withRuntime(r).skipped(condition)
+		if err != nil {
+			return false, fmt.Errorf("condition %q: %w", condition, err)
+		}
+		if isIf && condition != "" {
+			skipped = !skipped
+		}
+		if !skipped {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type inventoryImage struct {
+	name   string
+	target target
+}
+
+// inventoryImages returns the per-platform images and the manifest lists the
+// release pushes.
+func inventoryImages(project config.Project, vars templateVars) ([]inventoryImage, []string, error) {
+	var images []inventoryImage
+	var manifests []string
+	for _, docker := range project.Dockers {
+		t := target{os: docker.Goos, arch: docker.Goarch, arm: docker.Goarm}
+		published, err := publishedOnRuntime(t.vars(vars), docker.SkipPush, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("image: %w", err)
+		}
+		if !published {
+			continue
+		}
+		for _, image := range docker.ImageTemplates {
+			name, err := t.vars(vars).render(image)
+			if err != nil {
+				return nil, nil, fmt.Errorf("image: %w", err)
+			}
+			images = append(images, inventoryImage{name: name, target: t})
+		}
+	}
+	for _, manifest := range project.DockerManifests {
+		published, err := publishedOnRuntime(vars, manifest.SkipPush, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("manifest: %w", err)
+		}
+		if !published {
+			continue
+		}
+		name, err := vars.render(manifest.NameTemplate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("manifest: %w", err)
+		}
+		manifests = append(manifests, name)
+	}
+	for _, docker := range project.DockersV2 {
+		var names []string
+		for _, image := range docker.Images {
+			for _, tag := range docker.Tags {
+				tag, err := vars.render(tag)
+				if err != nil {
+					return nil, nil, fmt.Errorf("image tag: %w", err)
+				}
+				// Empty tags are not published.
+				if tag != "" {
+					names = append(names, image+":"+tag)
+				}
+			}
+		}
+		if len(docker.Platforms) != 1 {
+			manifests = append(manifests, names...)
+			continue
+		}
+		// Single platform images are the per-arch images, e.g. linux/arm/v7.
+		parts := strings.Split(docker.Platforms[0], "/")
+		t := target{os: parts[0], arch: parts[1]}
+		if len(parts) > 2 {
+			t.arm = strings.TrimPrefix(parts[2], "v")
+		}
+		for _, name := range names {
+			images = append(images, inventoryImage{name: name, target: t})
+		}
+	}
+	return images, manifests, nil
+}
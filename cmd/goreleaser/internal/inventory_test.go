@@ -0,0 +1,268 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+)
+
+func TestSignArtifactsMatch(t *testing.T) {
+	tests := []struct {
+		artifacts, typ string
+		want           bool
+	}{
+		{"all", "msi", true},
+		{"any", "archive", true},
+		{"archive", "archive", true},
+		{"archive", "binary", false},
+		{"package", "package", true},
+		{"package", "msi", false},
+		{"package", "pkg", false},
+		{"installer", "msi", true},
+		{"installer", "pkg", true},
+		{"installer", "package", false},
+		{"checksum", "checksum", true},
+		{"none", "archive", false},
+	}
+	for _, tt := range tests {
+		if got := signArtifactsMatch(tt.artifacts, tt.typ); got != tt.want {
+			t.Errorf("signArtifactsMatch(%q, %q) = %v, want %v", tt.artifacts, tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestInventoryInstallers(t *testing.T) {
+	chdirRoot(t)
+
+	project, err := BuildDistribution(coreDistro, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inventory, err := NewInventory(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := map[string]bool{}
+	for _, p := range inventory.Platforms {
+		for _, a := range p.Artifacts {
+			found[a.Type] = true
+			provenance := false
+			for _, signature := range a.Signatures {
+				provenance = provenance || strings.HasSuffix(signature, ".intoto.sigstore.json")
+			}
+			switch a.Type {
+			case "msi", "pkg":
+				// The SBOM and provenance signs select archives and packages only.
+				if a.SBOM != "" || a.SBOMChecksum != "" || provenance {
+					t.Errorf("installer %s has an SBOM %q or a provenance %v", a.Name, a.SBOM, provenance)
+				}
+				if a.Checksum == "" || len(a.Signatures) == 0 {
+					t.Errorf("installer %s isn't checksummed and signed: %+v", a.Name, a)
+				}
+			case "archive", "package":
+				if a.SBOM == "" || !provenance {
+					t.Errorf("%s %s has no SBOM or provenance: %+v", a.Type, a.Name, a)
+				}
+			}
+		}
+	}
+	for _, typ := range []string{"archive", "package", "msi", "pkg"} {
+		if !found[typ] {
+			t.Errorf("no %s in the inventory", typ)
+		}
+	}
+}
+
+// testProject is a small release of linux archives, packages and images and
+// windows MSIs, named with goreleaser's defaults where the repository uses them.
+func testProject() config.Project {
+	project := config.Project{
+		ProjectName: "otelcol",
+		Env: []string{
+			"CONTAINER_IMAGE_EPHEMERAL_TAG={{ if .IsNightly }}nightly{{ else }}latest{{ end }}",
+		},
+		Builds: []config.Build{
+			{ID: "otelcol-linux", Binary: "otelcol", Goos: []string{"linux"}, Goarch: []string{"amd64", "arm"}, Goarm: []string{"7"}},
+			{ID: "otelcol-windows", Binary: "otelcol", Goos: []string{"windows"}, Goarch: []string{"amd64"}},
+		},
+		Archives: []config.Archive{{IDs: []string{"otelcol-linux"}}},
+		MSI:      []config.MSI{{IDs: []string{"otelcol-windows"}, Name: "{{ .ProjectName }}_{{ .Version }}_windows_{{ .MsiArch }}"}},
+		Checksum: config.Checksum{Split: true},
+		Signs:    []config.Sign{{Artifacts: "all", Signature: "${artifact}.sigstore.json"}},
+		SBOMs:    []config.SBOM{{Artifacts: "archive"}},
+		Dockers: []config.Docker{
+			{Goos: "linux", Goarch: "amd64", ImageTemplates: []string{"otel/otelcol:{{ .Version }}-amd64", "otel/otelcol:{{ .Env.CONTAINER_IMAGE_EPHEMERAL_TAG }}-amd64"}, SkipPush: skipOnWindows},
+		},
+		DockerManifests: []config.DockerManifest{
+			{NameTemplate: "otel/otelcol:{{ .Version }}", SkipPush: skipOnWindows},
+			{NameTemplate: "otel/otelcol:" + floatingTag, SkipPush: skipFloatingTag},
+		},
+		Release:  config.Release{ExtraFiles: []config.ExtraFile{{Glob: "_build/components.cdx.json", NameTemplate: "otelcol_{{ .Version }}_components.cdx.json"}}},
+		Nightly:  config.Nightly{VersionTemplate: "{{ incpatch .Version}}-nightly.{{ .ShortCommit }}", TagName: "nightly-otelcol"},
+		Snapshot: config.Snapshot{VersionTemplate: "{{ .Version }}-SNAPSHOT-{{ .ShortCommit }}"},
+	}
+	project.NFPMs = []config.NFPM{{IDs: []string{"otelcol-linux"}, Formats: []string{"deb"}}}
+	project.NFPMs[0].PackageName = "otelcol"
+	return project
+}
+
+func TestRenderInventory(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    RenderOptions
+		version string
+		// want are the names of the linux/amd64 artifacts and images, the
+		// windows MSI, the manifest lists and the release files.
+		wantArchive  Artifact
+		wantPackage  string
+		wantMSI      string
+		wantImages   []string
+		wantManifest []string
+		wantFiles    []string
+	}{
+		{
+			name:    "release",
+			opts:    RenderOptions{Version: "0.160.0", Commit: "abc1234"},
+			version: "0.160.0",
+			wantArchive: Artifact{
+				Type:         "archive",
+				Name:         "otelcol_0.160.0_linux_amd64.tar.gz",
+				Checksum:     "otelcol_0.160.0_linux_amd64.tar.gz.sha256",
+				SBOM:         "otelcol_0.160.0_linux_amd64.tar.gz.sbom.json",
+				SBOMChecksum: "otelcol_0.160.0_linux_amd64.tar.gz.sbom.json.sha256",
+				Signatures: []string{
+					"otelcol_0.160.0_linux_amd64.tar.gz.sigstore.json",
+					"otelcol_0.160.0_linux_amd64.tar.gz.sha256.sigstore.json",
+					"otelcol_0.160.0_linux_amd64.tar.gz.sbom.json.sigstore.json",
+					"otelcol_0.160.0_linux_amd64.tar.gz.sbom.json.sha256.sigstore.json",
+				},
+			},
+			wantPackage:  "otelcol_0.160.0_linux_amd64.deb",
+			wantMSI:      "otelcol_0.160.0_windows_x64.msi",
+			wantImages:   []string{"otel/otelcol:0.160.0-amd64", "otel/otelcol:latest-amd64"},
+			wantManifest: []string{"otel/otelcol:0.160.0", "otel/otelcol:0.160"},
+			wantFiles:    []string{"otelcol_0.160.0_components.cdx.json"},
+		},
+		{
+			name:    "nightly",
+			opts:    RenderOptions{Version: "0.161.0", Commit: "abc1234", Nightly: true},
+			version: "0.161.1-nightly.abc1234",
+			wantArchive: Artifact{
+				Type:         "archive",
+				Name:         "otelcol_0.161.1-nightly.abc1234_linux_amd64.tar.gz",
+				Checksum:     "otelcol_0.161.1-nightly.abc1234_linux_amd64.tar.gz.sha256",
+				SBOM:         "otelcol_0.161.1-nightly.abc1234_linux_amd64.tar.gz.sbom.json",
+				SBOMChecksum: "otelcol_0.161.1-nightly.abc1234_linux_amd64.tar.gz.sbom.json.sha256",
+				Signatures: []string{
+					"otelcol_0.161.1-nightly.abc1234_linux_amd64.tar.gz.sigstore.json",
+					"otelcol_0.161.1-nightly.abc1234_linux_amd64.tar.gz.sha256.sigstore.json",
+					"otelcol_0.161.1-nightly.abc1234_linux_amd64.tar.gz.sbom.json.sigstore.json",
+					"otelcol_0.161.1-nightly.abc1234_linux_amd64.tar.gz.sbom.json.sha256.sigstore.json",
+				},
+			},
+			wantPackage: "otelcol_0.161.1-nightly.abc1234_linux_amd64.deb",
+			wantMSI:     "otelcol_0.161.1-nightly.abc1234_windows_x64.msi",
+			wantImages:  []string{"otel/otelcol:0.161.1-nightly.abc1234-amd64", "otel/otelcol:nightly-amd64"},
+			// Floating tags don't move on nightlies.
+			wantManifest: []string{"otel/otelcol:0.161.1-nightly.abc1234"},
+			wantFiles:    []string{"otelcol_0.161.1-nightly.abc1234_components.cdx.json"},
+		},
+		{
+			name:    "snapshot",
+			opts:    RenderOptions{Version: "0.160.0", Commit: "abc1234", Snapshot: true},
+			version: "0.160.0-SNAPSHOT-abc1234",
+			wantArchive: Artifact{
+				Type:         "archive",
+				Name:         "otelcol_0.160.0-SNAPSHOT-abc1234_linux_amd64.tar.gz",
+				Checksum:     "otelcol_0.160.0-SNAPSHOT-abc1234_linux_amd64.tar.gz.sha256",
+				SBOM:         "otelcol_0.160.0-SNAPSHOT-abc1234_linux_amd64.tar.gz.sbom.json",
+				SBOMChecksum: "otelcol_0.160.0-SNAPSHOT-abc1234_linux_amd64.tar.gz.sbom.json.sha256",
+				Signatures: []string{
+					"otelcol_0.160.0-SNAPSHOT-abc1234_linux_amd64.tar.gz.sigstore.json",
+					"otelcol_0.160.0-SNAPSHOT-abc1234_linux_amd64.tar.gz.sha256.sigstore.json",
+					"otelcol_0.160.0-SNAPSHOT-abc1234_linux_amd64.tar.gz.sbom.json.sigstore.json",
+					"otelcol_0.160.0-SNAPSHOT-abc1234_linux_amd64.tar.gz.sbom.json.sha256.sigstore.json",
+				},
+			},
+			wantPackage:  "otelcol_0.160.0-SNAPSHOT-abc1234_linux_amd64.deb",
+			wantMSI:      "otelcol_0.160.0-SNAPSHOT-abc1234_windows_x64.msi",
+			wantImages:   []string{"otel/otelcol:0.160.0-SNAPSHOT-abc1234-amd64", "otel/otelcol:latest-amd64"},
+			wantManifest: []string{"otel/otelcol:0.160.0-SNAPSHOT-abc1234"},
+			wantFiles:    []string{"otelcol_0.160.0-SNAPSHOT-abc1234_components.cdx.json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inventory, err := RenderInventory(testProject(), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if inventory.Distribution != "otelcol" || inventory.Version != tt.version {
+				t.Errorf("inventory of %s %s, want otelcol %s", inventory.Distribution, inventory.Version, tt.version)
+			}
+
+			var platforms []string
+			for _, p := range inventory.Platforms {
+				platforms = append(platforms, p.OS+"/"+p.Arch+"/"+p.Arm)
+			}
+			if want := []string{"linux/amd64/", "linux/arm/7", "windows/amd64/"}; !slices.Equal(platforms, want) {
+				t.Fatalf("platforms = %q, want %q", platforms, want)
+			}
+			linux, windows := inventory.Platforms[0], inventory.Platforms[2]
+
+			if len(linux.Artifacts) != 2 {
+				t.Fatalf("linux/amd64 artifacts = %+v, want an archive and a package", linux.Artifacts)
+			}
+			if got := linux.Artifacts[0]; !reflect.DeepEqual(got, tt.wantArchive) {
+				t.Errorf("archive = %+v, want %+v", got, tt.wantArchive)
+			}
+			if got := linux.Artifacts[1]; got.Type != "package" || got.Name != tt.wantPackage || got.SBOM != "" {
+				t.Errorf("package = %+v, want %s without SBOM", got, tt.wantPackage)
+			}
+			if len(windows.Artifacts) != 1 || windows.Artifacts[0].Type != "msi" || windows.Artifacts[0].Name != tt.wantMSI {
+				t.Errorf("windows artifacts = %+v, want %s", windows.Artifacts, tt.wantMSI)
+			}
+			if !slices.Equal(linux.Images, tt.wantImages) {
+				t.Errorf("images = %q, want %q", linux.Images, tt.wantImages)
+			}
+			if !slices.Equal(inventory.Manifests, tt.wantManifest) {
+				t.Errorf("manifests = %q, want %q", inventory.Manifests, tt.wantManifest)
+			}
+			if !slices.Equal(inventory.Files, tt.wantFiles) {
+				t.Errorf("files = %q, want %q", inventory.Files, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestNewInventoryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*config.Project)
+	}{
+		{"archive name", func(p *config.Project) { p.Archives[0].NameTemplate = "{{ .Unknown }}" }},
+		{"env", func(p *config.Project) { p.Env = append(p.Env, "BAD={{ .Env.MISSING }}") }},
+		{"image skip", func(p *config.Project) { p.Dockers[0].SkipPush = "{{ .Runtime.Os }}" }},
+		{"manifest skip", func(p *config.Project) { p.DockerManifests[0].SkipPush = "{{ if }}" }},
+		{"pkg if", func(p *config.Project) {
+			p.Builds = append(p.Builds, config.Build{ID: "otelcol-darwin", Goos: []string{"darwin"}, Goarch: []string{"arm64"}})
+			p.Pkgs = []config.MacOSPkg{{IDs: []string{"otelcol-darwin"}, Name: "otelcol", If: "{{ .Runtime.Os }}"}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := testProject()
+			tt.modify(&project)
+			if _, err := NewInventory(project); err == nil {
+				t.Error("NewInventory() succeeded")
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// vendor is the org.opencontainers.image.vendor of all images.
+const vendor = "OpenTelemetry Authors"
+
+// sourceMetadata is the image metadata read from the sources of a distribution
+// or binary.
+type sourceMetadata struct {
+	description string
+	// froms are the FROM images of the final stage of each Dockerfile, before
+	// build args are expanded.
+	froms map[string]string
+}
+
+// sourcesMetadata holds the metadata loaded by loadSourceMetadata, per source
+// directory.
+var sourcesMetadata = map[string]sourceMetadata{}
+
+// sourceDir returns the directory holding the sources, manifest and Dockerfiles
+// of a distribution or binary, relative to the repository root.
+func sourceDir(dist string) string {
+	switch dist {
+	case ocbBinary, opampBinary:
+		return filepath.Join("cmd", dist)
+	}
+	return filepath.Join("distributions", strings.TrimSuffix(dist, fipsSuffix))
+}
+
+// loadSourceMetadata reads the description and the Dockerfiles of a
+// distribution or binary, for the labels of its images. It must be called
+// before building the distribution.
+func loadSourceMetadata(dist string) error {
+	dir := sourceDir(dist)
+	description, err := distributionDescription(dir)
+	if err != nil {
+		return err
+	}
+	dockerfiles, err := filepath.Glob(filepath.Join(dir, "*ockerfile"))
+	if err != nil {
+		return err
+	}
+	metadata := sourceMetadata{description: description, froms: map[string]string{}}
+	for _, dockerfile := range dockerfiles {
+		from, err := finalStageFrom(dockerfile)
+		if err != nil {
+			return err
+		}
+		metadata.froms[filepath.Base(dockerfile)] = from
+	}
+	sourcesMetadata[dir] = metadata
+	return nil
+}
+
+// distributionDescription returns dist.description from the manifest.yaml in
+// a source directory. Binaries have no manifest and no description.
+func distributionDescription(dir string) (string, error) {
+	path := filepath.Join(dir, "manifest.yaml")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var manifest struct {
+		Dist struct {
+			Description string `yaml:"description"`
+		} `yaml:"dist"`
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return manifest.Dist.Description, nil
+}
+
+// finalStageFrom returns the image of the last FROM instruction of a
+// Dockerfile, skipping flags such as --platform.
+func finalStageFrom(dockerfile string) (string, error) {
+	data, err := os.ReadFile(dockerfile)
+	if err != nil {
+		return "", err
+	}
+
+	var from string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		from = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "--") {
+				from = field
+				break
+			}
+		}
+		if from == "" {
+			return "", fmt.Errorf("%s: FROM without image: %s", dockerfile, scanner.Text())
+		}
+	}
+	return from, scanner.Err()
+}
+
+// imageBase returns the name and digest of the base image of the final stage
+// of a Dockerfile, expanding the given build args. Images built from scratch
+// have no base.
+func imageBase(dist, dockerfile string, buildArgs map[string]string) (name, digest string) {
+	from := sourcesMetadata[sourceDir(dist)].froms[dockerfile]
+	from = os.Expand(from, func(arg string) string { return buildArgs[arg] })
+	if from == "" || from == "scratch" {
+		return "", ""
+	}
+
+	name, digest, _ = strings.Cut(from, "@")
+	return qualifiedImageName(name), digest
+}
+
+// qualifiedImageName returns the fully qualified form of an image name, e.g.
+// docker.io/library/alpine:3.24 for alpine:3.24.
+func qualifiedImageName(name string) string {
+	domain, _, found := strings.Cut(name, "/")
+	switch {
+	case !found:
+		return "docker.io/library/" + name
+	case !strings.ContainsAny(domain, ".:") && domain != "localhost":
+		return "docker.io/" + name
+	}
+	return name
+}
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFinalStageFrom(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "scratch",
+			dockerfile: "FROM alpine:3.24 AS certs\nFROM scratch\n",
+			want:       "scratch",
+		},
+		{
+			name:       "platform flag",
+			dockerfile: "FROM --platform=$BUILDPLATFORM golang:1.26 AS build\nFROM --platform=linux/amd64 alpine:3.24@sha256:28bd AS final\n",
+			want:       "alpine:3.24@sha256:28bd",
+		},
+		{
+			name:       "build arg",
+			dockerfile: "ARG WIN_VERSION\nfrom mcr.microsoft.com/windows/nanoserver:ltsc${WIN_VERSION}\n",
+			want:       "mcr.microsoft.com/windows/nanoserver:ltsc${WIN_VERSION}",
+		},
+		{
+			name:       "flags only",
+			dockerfile: "FROM --platform=linux/amd64\n",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Dockerfile")
+			if err := os.WriteFile(path, []byte(tt.dockerfile), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := finalStageFrom(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("finalStageFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("finalStageFrom() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceMetadataErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte("dist: ["), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := distributionDescription(dir); err == nil {
+		t.Error("expected an error for an invalid manifest.yaml")
+	}
+	if _, err := finalStageFrom(filepath.Join(dir, "Dockerfile")); err == nil {
+		t.Error("expected an error for a missing Dockerfile")
+	}
+}
@@ -13,4 +13,35 @@ var (
 	k8sArchs          = []string{"amd64", "arm64", "ppc64le", "riscv64", "s390x"}
 	ocbArchs          = []string{"amd64", "arm64", "ppc64le", "riscv64"}
 	opAmpArchs        = []string{"amd64", "arm64", "ppc64le"}
+	fipsArchs         = []string{"amd64", "arm64"}
 )
+
+// winContainerVersions are the Windows Server versions container images are built for.
+var winContainerVersions = []string{"2019", "2022", "2025"}
+
+// winContainerHosts are the Windows Server versions of the release runners
+// building the images of each Windows Server version: a host can't pull bases
+// newer than itself. The release workflows set WIN_HOST_VERSION on the runners,
+// defaultWinHost when unset.
+var winContainerHosts = map[string]string{
+	"2019": "2022",
+	"2022": "2022",
+	"2025": "2025",
+}
+
+// defaultWinHost is the Windows Server version of the runner releasing the
+// windows archives and packages. Runners of other versions only push images.
+const defaultWinHost = "2022"
+
+// winBaseImages are the Windows base images, per family and Windows Server
+// version, passed to Windows.dockerfile as WIN_IMAGE. Renovate pins them by
+// digest. Until it has, make check-dockerfiles leaves them out of its check
+// with -allow-unpinned.
+var winBaseImages = map[string]string{
+	"nanoserver:2019": "mcr.microsoft.com/windows/nanoserver:ltsc2019",
+	"nanoserver:2022": "mcr.microsoft.com/windows/nanoserver:ltsc2022",
+	"nanoserver:2025": "mcr.microsoft.com/windows/nanoserver:ltsc2025",
+	"servercore:2019": "mcr.microsoft.com/windows/servercore:ltsc2019",
+	"servercore:2022": "mcr.microsoft.com/windows/servercore:ltsc2022",
+	"servercore:2025": "mcr.microsoft.com/windows/servercore:ltsc2025",
+}
@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// signingMode selects how artifacts and images are signed.
+type signingMode string
+
+const (
+	// signingKeyless signs with cosign and a short-lived certificate of the CI
+	// identity, recorded in the transparency log.
+	signingKeyless signingMode = "keyless"
+	// signingKey signs with a cosign key file, without transparency log.
+	signingKey signingMode = "key"
+	// signingKMS signs with a cosign KMS key reference, e.g.
+	// awskms:///alias/otelcol, without transparency log.
+	signingKMS signingMode = "kms"
+	// signingGPG signs with detached, armored GPG signatures, e.g. for deb and
+	// rpm repositories. Images are not signed or attested.
+	signingGPG signingMode = "gpg"
+)
+
+// signing configures how the artifacts and images of a distribution are signed.
+type signing struct {
+	mode signingMode
+	// key is the cosign key file, the KMS reference or the GPG key ID.
+	key string
+}
+
+// artifactSigning is how the artifacts and images of every distribution are
+// signed.
+var artifactSigning = signing{mode: signingKeyless}
+
+// ConfigureSigning sets how the artifacts and images of all distributions are
+// signed, for rebuilders without access to keyless signing. key is a cosign key
+// file, a KMS reference or a GPG key ID, depending on mode. It must be called
+// before building a distribution.
+func ConfigureSigning(mode, key string) error {
+	s := signing{mode: signingMode(mode), key: key}
+	switch s.mode {
+	case signingKeyless:
+		if key != "" {
+			return fmt.Errorf("keyless signing takes no key")
+		}
+	case signingKey:
+		if key == "" {
+			return fmt.Errorf("key signing requires a key file")
+		}
+	case signingKMS:
+		if !strings.Contains(key, "://") {
+			return fmt.Errorf("kms signing requires a KMS reference, e.g. awskms:///alias/otelcol, got %q", key)
+		}
+	case signingGPG:
+		// An empty key signs with the default GPG key.
+	default:
+		return fmt.Errorf("unknown signing mode %q, want keyless, key, kms or gpg", mode)
+	}
+	artifactSigning = s
+	return nil
+}
+
+// cosign reports whether the mode signs with cosign, so that images can be
+// signed and attested.
+func (s signing) cosign() bool {
+	return s.mode != signingGPG
+}
+
+// bundleSuffix returns the suffix of the cosign bundles. Keyless bundles are
+// sigstore.json files, bundles of a key are named apart as they hold no
+// certificate nor transparency log entry.
+func (s signing) bundleSuffix() string {
+	if s.mode == signingKeyless {
+		return ".sigstore.json"
+	}
+	return ".bundle"
+}
+
+// signature returns the signature file name template of an artifact.
+func (s signing) signature() string {
+	if s.mode == signingGPG {
+		return "${artifact}.asc"
+	}
+	return "${artifact}" + s.bundleSuffix()
+}
+
+// provenance returns the provenance bundle file name template of an artifact.
+func (s signing) provenance() string {
+	return "${artifact}.intoto" + s.bundleSuffix()
+}
+
+// cosignKeyArgs returns the cosign arguments selecting the signing key.
+func (s signing) cosignKeyArgs() []string {
+	switch s.mode {
+	case signingKey, signingKMS:
+		return []string{"--key=" + s.key, "--tlog-upload=false"}
+	}
+	return nil
+}
+
+// cosignEnv returns the environment selecting the signing key of the tools
+// attaching attestations.
+func (s signing) cosignEnv() []string {
+	switch s.mode {
+	case signingKey, signingKMS:
+		return []string{"COSIGN_KEY=" + s.key}
+	}
+	return nil
+}
+
+// gpgArgs returns the arguments of a detached, armored GPG signature.
+func (s signing) gpgArgs() []string {
+	args := []string{"--batch"}
+	if s.key != "" {
+		args = append(args, "--local-user="+s.key)
+	}
+	return append(args, "--output=${signature}", "--detach-sign", "--armor", "${artifact}")
+}
@@ -0,0 +1,190 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+)
+
+// chdirRoot runs the test from the repository root, where the distribution
+// sources are read from.
+func chdirRoot(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../../.."); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// findSign returns the sign with the given ID.
+func findSign(signs []config.Sign, id string) (config.Sign, bool) {
+	i := slices.IndexFunc(signs, func(s config.Sign) bool { return s.ID == id })
+	if i < 0 {
+		return config.Sign{}, false
+	}
+	return signs[i], true
+}
+
+func TestConfigureSigning(t *testing.T) {
+	chdirRoot(t)
+	t.Cleanup(func() { artifactSigning = signing{mode: signingKeyless} })
+
+	tests := []struct {
+		mode, key         string
+		wantCmd           string
+		wantArgs          []string
+		wantSignature     string
+		wantProvenance    string
+		wantProvenanceEnv []string
+		wantDockerArgs    []string
+	}{
+		{
+			mode:           "keyless",
+			wantCmd:        "cosign",
+			wantArgs:       []string{"sign-blob", "--bundle=${signature}", "${artifact}"},
+			wantSignature:  "${artifact}.sigstore.json",
+			wantProvenance: "${artifact}.intoto.sigstore.json",
+			wantDockerArgs: []string{"sign", "${artifact}"},
+		},
+		{
+			mode:              "key",
+			key:               "cosign.key",
+			wantCmd:           "cosign",
+			wantArgs:          []string{"sign-blob", "--key=cosign.key", "--tlog-upload=false", "--bundle=${signature}", "${artifact}"},
+			wantSignature:     "${artifact}.bundle",
+			wantProvenance:    "${artifact}.intoto.bundle",
+			wantProvenanceEnv: []string{"COSIGN_KEY=cosign.key"},
+			wantDockerArgs:    []string{"sign", "--key=cosign.key", "--tlog-upload=false", "${artifact}"},
+		},
+		{
+			mode:              "kms",
+			key:               "awskms:///alias/otelcol",
+			wantCmd:           "cosign",
+			wantArgs:          []string{"sign-blob", "--key=awskms:///alias/otelcol", "--tlog-upload=false", "--bundle=${signature}", "${artifact}"},
+			wantSignature:     "${artifact}.bundle",
+			wantProvenance:    "${artifact}.intoto.bundle",
+			wantProvenanceEnv: []string{"COSIGN_KEY=awskms:///alias/otelcol"},
+			wantDockerArgs:    []string{"sign", "--key=awskms:///alias/otelcol", "--tlog-upload=false", "${artifact}"},
+		},
+		{
+			mode:          "gpg",
+			key:           "releases@opentelemetry.io",
+			wantCmd:       "gpg",
+			wantArgs:      []string{"--batch", "--local-user=releases@opentelemetry.io", "--output=${signature}", "--detach-sign", "--armor", "${artifact}"},
+			wantSignature: "${artifact}.asc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			if err := ConfigureSigning(tt.mode, tt.key); err != nil {
+				t.Fatal(err)
+			}
+			project, err := BuildDistribution(coreDistro, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sign, ok := findSign(project.Signs, "")
+			if !ok {
+				t.Fatalf("no default sign in %v", project.Signs)
+			}
+			if sign.Cmd != tt.wantCmd || sign.Signature != tt.wantSignature || !slices.Equal(sign.Args, tt.wantArgs) {
+				t.Errorf("sign = %s %q -> %s, want %s %q -> %s", sign.Cmd, sign.Args, sign.Signature, tt.wantCmd, tt.wantArgs, tt.wantSignature)
+			}
+
+			for _, artifacts := range []string{"archive", "package"} {
+				provenance, ok := findSign(project.Signs, "provenance-"+artifacts)
+				if tt.wantProvenance == "" {
+					if ok {
+						t.Errorf("%s provenance signed with %s", artifacts, tt.mode)
+					}
+					continue
+				}
+				if !ok {
+					t.Fatalf("no %s provenance sign", artifacts)
+				}
+				if provenance.Signature != tt.wantProvenance || !slices.Equal(provenance.Env, tt.wantProvenanceEnv) {
+					t.Errorf("%s provenance = %s %q, want %s %q", artifacts, provenance.Signature, provenance.Env, tt.wantProvenance, tt.wantProvenanceEnv)
+				}
+			}
+
+			if tt.wantDockerArgs == nil {
+				if len(project.DockerSigns) > 0 {
+					t.Errorf("images signed with %s: %v", tt.mode, project.DockerSigns)
+				}
+				return
+			}
+			dockerSign, ok := findSign(project.DockerSigns, "")
+			if !ok {
+				t.Fatalf("no default docker sign in %v", project.DockerSigns)
+			}
+			if !slices.Equal(dockerSign.Args, tt.wantDockerArgs) {
+				t.Errorf("docker sign args = %q, want %q", dockerSign.Args, tt.wantDockerArgs)
+			}
+			for _, s := range project.DockerSigns {
+				if s.ID != "" && !slices.Equal(s.Env, tt.wantProvenanceEnv) {
+					t.Errorf("docker sign %s env = %q, want %q", s.ID, s.Env, tt.wantProvenanceEnv)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigureSigningInventory(t *testing.T) {
+	chdirRoot(t)
+	t.Cleanup(func() { artifactSigning = signing{mode: signingKeyless} })
+
+	if err := ConfigureSigning("key", "cosign.key"); err != nil {
+		t.Fatal(err)
+	}
+	project, err := BuildDistribution(coreDistro, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inventory, err := NewInventory(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signatures int
+	for _, p := range inventory.Platforms {
+		for _, a := range p.Artifacts {
+			for _, signature := range a.Signatures {
+				signatures++
+				if strings.HasSuffix(signature, ".sigstore.json") {
+					t.Errorf("%s signed with a key as a sigstore bundle: %s", a.Name, signature)
+				}
+			}
+		}
+	}
+	if signatures == 0 {
+		t.Error("no signatures in the inventory")
+	}
+}
+
+func TestConfigureSigningErrors(t *testing.T) {
+	t.Cleanup(func() { artifactSigning = signing{mode: signingKeyless} })
+
+	for _, tt := range []struct{ mode, key string }{
+		{"keyless", "cosign.key"},
+		{"key", ""},
+		{"kms", "alias/otelcol"},
+		{"pgp", ""},
+	} {
+		if err := ConfigureSigning(tt.mode, tt.key); err == nil {
+			t.Errorf("ConfigureSigning(%q, %q) succeeded", tt.mode, tt.key)
+		}
+	}
+	if artifactSigning.mode != signingKeyless {
+		t.Errorf("invalid configurations changed the signing mode to %s", artifactSigning.mode)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+)
+
+// releaseRuntime is a runner the release workflows run goreleaser on.
+type releaseRuntime struct {
+	goos string
+	// winHost is the Windows Server version of windows runners, see
+	// winContainerHosts.
+	winHost string
+}
+
+// releaseRuntimes returns the runners of a release: linux, windows for every
+// Windows Server host and darwin for the darwin targets only. Images and
+// manifests pushed from any of them are published.
+func releaseRuntimes() []releaseRuntime {
+	runtimes := []releaseRuntime{{goos: "linux"}}
+	for _, host := range slices.Compact(slices.Sorted(maps.Values(winContainerHosts))) {
+		runtimes = append(runtimes, releaseRuntime{goos: "windows", winHost: host})
+	}
+	return append(runtimes, releaseRuntime{goos: "darwin"})
+}
+
+// templateVars are the release values goreleaser name templates are rendered
+// with, e.g. .Version. Target specific values, e.g. .Os, are added per artifact.
+type templateVars map[string]any
+
+// placeholderVars keeps the release values as goreleaser templates, so that
+// rendered names only depend on the version and commit of a release.
+func placeholderVars() templateVars {
+	vars := templateVars{
+		"IsNightly":  false,
+		"IsSnapshot": false,
+	}
+	for _, key := range []string{"Version", "RawVersion", "Major", "Minor", "Patch", "Tag", "ShortCommit", "FullCommit", "Commit", "CommitDate", "CommitTimestamp"} {
+		vars[key] = fmt.Sprintf("{{ .%s }}", key)
+	}
+	return vars
+}
+
+// RenderOptions are the release values names are rendered for.
+type RenderOptions struct {
+	// Version is the released version, e.g. 0.160.0. Nightly and snapshot
+	// versions are derived from it.
+	Version string
+	// Commit is the released commit, full or abbreviated.
+	Commit   string
+	Nightly  bool
+	Snapshot bool
+}
+
+// releaseVars returns the variables of a release of the project. Nightly and
+// snapshot releases are versioned from the project's version templates.
+func releaseVars(project config.Project, opts RenderOptions) (templateVars, error) {
+	if opts.Nightly && opts.Snapshot {
+		return nil, errors.New("a release can't be both nightly and snapshot")
+	}
+	vars, err := placeholderVars().withVersion(opts.Version)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Commit != "" {
+		short := opts.Commit
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		vars = vars.with(map[string]any{"Commit": opts.Commit, "FullCommit": opts.Commit, "ShortCommit": short})
+	}
+	// Monorepo tags carry a prefix, e.g. cmd/builder/v0.160.0.
+	vars["Tag"] = strings.TrimSuffix(project.Monorepo.TagPrefix, "v") + "v" + vars["Version"].(string)
+
+	switch {
+	case opts.Nightly:
+		version, err := vars.render(project.Nightly.VersionTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("nightly version: %w", err)
+		}
+		vars["IsNightly"] = true
+		vars["Version"] = version
+		// Channels keeping several releases tag them per version.
+		if vars["Tag"], err = vars.render(project.Nightly.TagName); err != nil {
+			return nil, fmt.Errorf("nightly tag: %w", err)
+		}
+	case opts.Snapshot:
+		version, err := vars.render(project.Snapshot.VersionTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot version: %w", err)
+		}
+		vars["IsSnapshot"] = true
+		vars["Version"] = version
+	}
+	return vars, nil
+}
+
+// withVersion sets .Version and its parts from a semantic version.
+func (v templateVars) withVersion(version string) (templateVars, error) {
+	major, minor, patch, err := parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return v.with(map[string]any{
+		"Version":    strings.TrimPrefix(version, "v"),
+		"RawVersion": fmt.Sprintf("%d.%d.%d", major, minor, patch),
+		"Major":      major,
+		"Minor":      minor,
+		"Patch":      patch,
+	}), nil
+}
+
+// parseVersion returns the numbers of a [v]MAJOR.MINOR.PATCH[-PRERELEASE] version.
+func parseVersion(version string) (major, minor, patch uint64, err error) {
+	core, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "-")
+	core, _, _ = strings.Cut(core, "+")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("invalid version %q, expected MAJOR.MINOR.PATCH", version)
+	}
+	var numbers [3]uint64
+	for i, part := range parts {
+		if numbers[i], err = strconv.ParseUint(part, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid version %q: %w", version, err)
+		}
+	}
+	return numbers[0], numbers[1], numbers[2], nil
+}
+
+// incVersion implements the goreleaser incmajor, incminor and incpatch functions.
+func incVersion(part int) func(string) (string, error) {
+	return func(version string) (string, error) {
+		major, minor, patch, err := parseVersion(version)
+		if err != nil {
+			return "", err
+		}
+		switch part {
+		case 0:
+			major, minor, patch = major+1, 0, 0
+		case 1:
+			minor, patch = minor+1, 0
+		default:
+			patch++
+		}
+		prefix := ""
+		if strings.HasPrefix(version, "v") {
+			prefix = "v"
+		}
+		return fmt.Sprintf("%s%d.%d.%d", prefix, major, minor, patch), nil
+	}
+}
+
+// with returns a copy of the variables with the given values added.
+func (v templateVars) with(values map[string]any) templateVars {
+	vars := maps.Clone(v)
+	maps.Copy(vars, values)
+	return vars
+}
+
+// withEnv renders the project env, in order, and adds it as .Env.
+func (v templateVars) withEnv(env []string) (templateVars, error) {
+	rendered := map[string]string{}
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		value, err := v.with(map[string]any{"Env": rendered}).render(value)
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", key, err)
+		}
+		rendered[key] = value
+	}
+	return v.with(map[string]any{"Env": rendered}), nil
+}
+
+// withRuntime sets .Runtime.Goos to the OS goreleaser runs on and, on windows
+// runners, WIN_HOST_VERSION to their Windows Server version.
+func (v templateVars) withRuntime(r releaseRuntime) templateVars {
+	values := map[string]any{"Runtime": map[string]string{"Goos": r.goos, "Goarch": "amd64"}}
+	if r.winHost != "" {
+		env, _ := v["Env"].(map[string]string)
+		env = maps.Clone(env)
+		if env == nil {
+			env = map[string]string{}
+		}
+		env["WIN_HOST_VERSION"] = r.winHost
+		values["Env"] = env
+	}
+	return v.with(values)
+}
+
+// envOrDefault implements the goreleaser envOrDefault function, reading .Env.
+func (v templateVars) envOrDefault(key, value string) string {
+	env, _ := v["Env"].(map[string]string)
+	if env[key] != "" {
+		return env[key]
+	}
+	return value
+}
+
+// render evaluates a goreleaser template. Only the functions used in the
+// names of this repository are supported.
+func (v templateVars) render(s string) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Funcs(templateFuncs).Funcs(template.FuncMap{"envOrDefault": v.envOrDefault}).Parse(s)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, map[string]any(v)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// skipped renders a goreleaser skip or if condition, true when it renders to "true".
+func (v templateVars) skipped(condition string) (bool, error) {
+	if condition == "" {
+		return false, nil
+	}
+	rendered, err := v.render(condition)
+	return strings.TrimSpace(rendered) == "true", err
+}
+
+var templateFuncs = template.FuncMap{
+	"incmajor":   incVersion(0),
+	"incminor":   incVersion(1),
+	"incpatch":   incVersion(2),
+	"tolower":    strings.ToLower,
+	"toupper":    strings.ToUpper,
+	"trimprefix": strings.TrimPrefix,
+	"trimsuffix": strings.TrimSuffix,
+	"replace":    strings.ReplaceAll,
+}
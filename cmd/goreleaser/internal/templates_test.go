@@ -0,0 +1,221 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
+)
+
+func TestIncVersion(t *testing.T) {
+	tests := []struct {
+		part    int
+		version string
+		want    string
+		wantErr bool
+	}{
+		{part: 0, version: "0.160.3", want: "1.0.0"},
+		{part: 1, version: "0.160.3", want: "0.161.0"},
+		{part: 2, version: "0.160.3", want: "0.160.4"},
+		{part: 1, version: "v0.160.0-rc.202610190200", want: "v0.161.0"},
+		{part: 2, version: "0.160.0+build", want: "0.160.1"},
+		{part: 2, version: "0.160", wantErr: true},
+		{part: 2, version: "0.x.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := incVersion(tt.part)(tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("incVersion(%d)(%q) error = %v, want error %v", tt.part, tt.version, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("incVersion(%d)(%q) = %q, want %q", tt.part, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseVars(t *testing.T) {
+	project := config.Project{
+		Nightly: config.Nightly{
+			VersionTemplate: "{{ incpatch .Version}}-nightly.{{ .ShortCommit }}",
+			TagName:         "nightly-otelcol",
+		},
+		Snapshot: config.Snapshot{VersionTemplate: "{{ .Version }}-SNAPSHOT-{{ .ShortCommit }}"},
+	}
+	rc := project
+	rc.Nightly = config.Nightly{
+		VersionTemplate: "{{ incminor .Version }}-rc.{{ .ShortCommit }}",
+		TagName:         "rc-otelcol-{{ .Version }}",
+	}
+	binary := project
+	binary.Monorepo = config.Monorepo{TagPrefix: "cmd/builder/"}
+
+	tests := []struct {
+		name      string
+		project   config.Project
+		opts      RenderOptions
+		wantVar   map[string]any
+		wantError bool
+	}{
+		{
+			name:    "release",
+			project: project,
+			opts:    RenderOptions{Version: "v0.160.0", Commit: "0123456789abcdef"},
+			wantVar: map[string]any{
+				"Version":     "0.160.0",
+				"RawVersion":  "0.160.0",
+				"Minor":       uint64(160),
+				"Tag":         "v0.160.0",
+				"ShortCommit": "0123456",
+				"FullCommit":  "0123456789abcdef",
+				"IsNightly":   false,
+				"IsSnapshot":  false,
+			},
+		},
+		{
+			name:    "release without commit",
+			project: project,
+			opts:    RenderOptions{Version: "0.160.0"},
+			wantVar: map[string]any{"Version": "0.160.0", "ShortCommit": "{{ .ShortCommit }}"},
+		},
+		{
+			name:    "monorepo release",
+			project: binary,
+			opts:    RenderOptions{Version: "0.160.0"},
+			wantVar: map[string]any{"Tag": "cmd/builder/v0.160.0"},
+		},
+		{
+			name:    "nightly",
+			project: project,
+			opts:    RenderOptions{Version: "0.161.0", Commit: "abc1234", Nightly: true},
+			wantVar: map[string]any{"Version": "0.161.1-nightly.abc1234", "Tag": "nightly-otelcol", "IsNightly": true},
+		},
+		{
+			name:    "rc nightly",
+			project: rc,
+			opts:    RenderOptions{Version: "0.160.0", Commit: "abc1234", Nightly: true},
+			wantVar: map[string]any{"Version": "0.161.0-rc.abc1234", "Tag": "rc-otelcol-0.161.0-rc.abc1234"},
+		},
+		{
+			name:    "snapshot",
+			project: project,
+			opts:    RenderOptions{Version: "0.160.0", Commit: "abc1234", Snapshot: true},
+			wantVar: map[string]any{"Version": "0.160.0-SNAPSHOT-abc1234", "IsSnapshot": true, "IsNightly": false},
+		},
+		{
+			name:      "nightly and snapshot",
+			project:   project,
+			opts:      RenderOptions{Version: "0.160.0", Nightly: true, Snapshot: true},
+			wantError: true,
+		},
+		{
+			name:      "invalid version",
+			project:   project,
+			opts:      RenderOptions{Version: "latest"},
+			wantError: true,
+		},
+		{
+			name:      "invalid nightly template",
+			project:   config.Project{Nightly: config.Nightly{VersionTemplate: "{{ .Unknown }}"}},
+			opts:      RenderOptions{Version: "0.160.0", Nightly: true},
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars, err := releaseVars(tt.project, tt.opts)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("releaseVars() error = %v, want error %v", err, tt.wantError)
+			}
+			for key, want := range tt.wantVar {
+				if got := vars[key]; got != want {
+					t.Errorf(".%s = %v (%T), want %v (%T)", key, got, got, want, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	vars, err := placeholderVars().with(map[string]any{"IsNightly": true}).withEnv([]string{
+		"CONTAINER_IMAGE_EPHEMERAL_TAG={{ if .IsNightly }}nightly{{ else }}latest{{ end }}",
+		"IMAGE_TAG={{ .Env.CONTAINER_IMAGE_EPHEMERAL_TAG }}-amd64",
+		"CGO_ENABLED=0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := vars["Env"].(map[string]string)
+	if env["CONTAINER_IMAGE_EPHEMERAL_TAG"] != "nightly" || env["IMAGE_TAG"] != "nightly-amd64" || env["CGO_ENABLED"] != "0" {
+		t.Errorf("env = %v", env)
+	}
+
+	if _, err := placeholderVars().withEnv([]string{"A={{ .Env.B }}", "B=b"}); err == nil {
+		t.Error("env referring to a later variable rendered")
+	}
+}
+
+func TestSkipped(t *testing.T) {
+	vars := placeholderVars().with(map[string]any{"Env": map[string]string{}})
+	tests := []struct {
+		condition string
+		runtime   releaseRuntime
+		nightly   bool
+		want      bool
+		wantErr   bool
+	}{
+		{condition: "", runtime: releaseRuntime{goos: "linux"}},
+		{condition: "true", runtime: releaseRuntime{goos: "linux"}, want: true},
+		{condition: skipOnWindows, runtime: releaseRuntime{goos: "linux"}},
+		{condition: skipOnWindows, runtime: releaseRuntime{goos: "windows", winHost: "2022"}, want: true},
+		{condition: skipFloatingTag, runtime: releaseRuntime{goos: "linux"}, nightly: true, want: true},
+		{condition: skipFloatingTag, runtime: releaseRuntime{goos: "linux"}},
+		{condition: skipOffWinHost("2025"), runtime: releaseRuntime{goos: "windows", winHost: "2022"}, want: true},
+		{condition: skipOffWinHost("2025"), runtime: releaseRuntime{goos: "windows", winHost: "2025"}},
+		{condition: skipOffWinHost("2022"), runtime: releaseRuntime{goos: "linux"}, want: true},
+		{condition: "{{ .Runtime.Os }}", runtime: releaseRuntime{goos: "linux"}, wantErr: true},
+		{condition: "{{ if }}", runtime: releaseRuntime{goos: "linux"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := vars.with(map[string]any{"IsNightly": tt.nightly}).withRuntime(tt.runtime).skipped(tt.condition)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("skipped(%q) on %v error = %v, want error %v", tt.condition, tt.runtime, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("skipped(%q) on %v = %v, want %v", tt.condition, tt.runtime, got, tt.want)
+		}
+	}
+}
+
+func TestPublishedOnRuntime(t *testing.T) {
+	vars := placeholderVars().with(map[string]any{"Env": map[string]string{}})
+	tests := []struct {
+		condition string
+		isIf      bool
+		want      bool
+		wantErr   bool
+	}{
+		{condition: "", want: true},
+		{condition: "", isIf: true, want: true},
+		{condition: "true"},
+		{condition: skipOnWindows, want: true},
+		{condition: skipOffWinHost("2025"), want: true},
+		{condition: `{{ eq .Runtime.Goos "darwin" }}`, isIf: true, want: true},
+		{condition: `{{ eq .Runtime.Goos "aix" }}`, isIf: true},
+		{condition: "{{ .Env.MISSING }}", wantErr: true},
+		{condition: "{{ unknown }}", isIf: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := publishedOnRuntime(vars, tt.condition, tt.isIf)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("publishedOnRuntime(%q, %v) error = %v, want error %v", tt.condition, tt.isIf, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("publishedOnRuntime(%q, %v) = %v, want %v", tt.condition, tt.isIf, got, tt.want)
+		}
+	}
+}
@@ -4,10 +4,15 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/goreleaser/goreleaser-pro/v2/pkg/config"
 	"github.com/open-telemetry/opentelemetry-collector-releases/cmd/goreleaser/internal"
 	"go.yaml.in/yaml/v3"
 )
@@ -15,15 +20,35 @@ import (
 var (
 	distFlag               = flag.String("d", "", "Collector distributions to build")
 	contribBuildOrRestFlag = flag.Bool("generate-build-step", false, "Collector Contrib distribution only - switch between build and package config file - set to true to generate build step, false to generate package step")
+	imageReposFlag         = flag.String("image-repositories", "", "Comma-separated container image repositories, defaults to otel and ghcr.io/open-telemetry/opentelemetry-collector-releases")
+	imageNameFlag          = flag.String("image-name-template", "", "Go template for container image names, with .Distribution (e.g. otelcol-contrib) and .Name (e.g. opentelemetry-collector-contrib)")
+	imageTagsFlag          = flag.String("image-extra-tags", "", "Comma-separated goreleaser tag templates published in addition to the version and latest/nightly tags, e.g. '{{ .Major }}.{{ .Minor }}'")
+	dockersV2Flag          = flag.Bool("dockers-v2", false, "Build linux container images with multi-platform dockers_v2 instead of per-arch dockers and manifests")
+	dockersV2ArchTagsFlag  = flag.Bool("dockers-v2-arch-tags", false, "With -dockers-v2, also publish the per-arch image tags, e.g. 0.159.0-amd64")
+	signingFlag            = flag.String("signing", "keyless", "How artifacts and images are signed: keyless, key (cosign key file), kms (cosign KMS reference) or gpg (detached .asc signatures, images unsigned)")
+	signingKeyFlag         = flag.String("signing-key", "", "Cosign key file or KMS reference, or GPG key ID, for the -signing mode")
+	fipsFlag               = flag.Bool("fips", false, "Generate the FIPS 140-3 flavour of the distribution (otelcol, otelcol-contrib and otelcol-k8s only)")
+	channelFlag            = flag.String("channel", "nightly", "Release channel released with goreleaser --nightly: nightly (single unpublished release), rc (published prereleases, last 3 kept) or beta (published prereleases, last 5 kept)")
+	inventoryFlag          = flag.Bool("inventory", false, "Print the JSON inventory of the artifacts and images released, instead of the goreleaser configuration")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		render(os.Args[2:])
+		return
+	}
 	flag.Parse()
 
-	if len(*distFlag) == 0 {
-		log.Fatal("no distribution to build")
+	project := buildProject()
+
+	if *inventoryFlag {
+		inventory, err := internal.NewInventory(project)
+		if err != nil {
+			log.Fatal(err)
+		}
+		writeJSON(inventory)
+		return
 	}
-	project := internal.BuildDistribution(*distFlag, *contribBuildOrRestFlag)
 
 	os.Stdout.WriteString("# yaml-language-server: $schema=https://goreleaser.com/static/schema-pro.json\n")
 	e := yaml.NewEncoder(os.Stdout)
@@ -32,3 +57,132 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// buildProject builds the goreleaser configuration selected by the flags.
+func buildProject() config.Project {
+	if len(*distFlag) == 0 {
+		log.Fatal("no distribution to build")
+	}
+	if err := internal.ConfigureImages(splitList(*imageReposFlag), *imageNameFlag, splitList(*imageTagsFlag)); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := internal.ConfigureSigning(*signingFlag, *signingKeyFlag); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := internal.UseReleaseChannel(*channelFlag); err != nil {
+		log.Fatal(err)
+	}
+
+	if *dockersV2Flag {
+		internal.UseDockersV2(*dockersV2ArchTagsFlag)
+	}
+
+	var project config.Project
+	var err error
+	if *fipsFlag {
+		project, err = internal.BuildFIPSDistribution(*distFlag)
+	} else {
+		project, err = internal.BuildDistribution(*distFlag, *contribBuildOrRestFlag)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return project
+}
+
+// render prints the names the configuration selected by the flags renders to
+// for a release: the archives, packages and images of every build target.
+func render(args []string) {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	// The configuration flags select what is rendered.
+	flag.VisitAll(func(f *flag.Flag) {
+		if f.Name != "inventory" {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	version := fs.String("version", "", "Version to render the names for, e.g. 0.160.0")
+	commit := fs.String("commit", "", "Commit to render the names for, required for nightly releases")
+	nightly := fs.Bool("nightly", false, "Render the names of a nightly release from the version")
+	snapshot := fs.Bool("snapshot", false, "Render the names of a snapshot build from the version")
+	jsonOutput := fs.Bool("json", false, "Print the rendered inventory as JSON")
+	_ = fs.Parse(args)
+
+	if *version == "" {
+		log.Fatal("render: -version is required")
+	}
+	if *nightly && *commit == "" {
+		log.Fatal("render: nightly versions include the commit, -commit is required")
+	}
+
+	inventory, err := internal.RenderInventory(buildProject(), internal.RenderOptions{
+		Version:  *version,
+		Commit:   *commit,
+		Nightly:  *nightly,
+		Snapshot: *snapshot,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *jsonOutput {
+		writeJSON(inventory)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "version\t%s\n", inventory.Version)
+	for _, p := range inventory.Platforms {
+		platform := p.OS + "/" + p.Arch
+		if p.Arm != "" {
+			platform += "/v" + p.Arm
+		}
+		for _, a := range p.Artifacts {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", platform, a.Type, a.Name)
+			if a.Checksum != "" {
+				fmt.Fprintf(w, "%s\tchecksum\t%s\n", platform, a.Checksum)
+			}
+			if a.SBOM != "" {
+				fmt.Fprintf(w, "%s\tsbom\t%s\n", platform, a.SBOM)
+			}
+			if a.SBOMChecksum != "" {
+				fmt.Fprintf(w, "%s\tchecksum\t%s\n", platform, a.SBOMChecksum)
+			}
+			for _, signature := range a.Signatures {
+				fmt.Fprintf(w, "%s\tsignature\t%s\n", platform, signature)
+			}
+		}
+		for _, image := range p.Images {
+			fmt.Fprintf(w, "%s\timage\t%s\n", platform, image)
+		}
+	}
+	for _, manifest := range inventory.Manifests {
+		fmt.Fprintf(w, "all\tmanifest\t%s\n", manifest)
+	}
+	for _, file := range inventory.Files {
+		fmt.Fprintf(w, "all\tfile\t%s\n", file)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeJSON(v any) {
+	e := json.NewEncoder(os.Stdout)
+	e.SetIndent("", "  ")
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// splitList splits a comma-separated flag value, ignoring empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// image-sbom generates the SBOM of a pushed container image with syft and
+// attaches it to the image as a cosign attestation, typed after the SBOM
+// format. goreleaser's own SBOM step only covers files, so this is run after
+// each image push, including the Windows ones that syft can't scan locally on
+// Linux, see withImageSBOMs in cmd/goreleaser. The attestations are signed as
+// described in internal/cosign.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/internal/cosign"
+)
+
+// predicateTypes maps the supported syft output formats to their cosign
+// attestation types.
+var predicateTypes = map[string]string{
+	"spdx-json":      "spdxjson",
+	"cyclonedx-json": "cyclonedx",
+}
+
+var (
+	imageFlag  = flag.String("image", "", "Container image reference, with digest, to attest")
+	formatFlag = flag.String("format", "spdx-json", "SBOM format, one of "+strings.Join(formats(), ", "))
+)
+
+func main() {
+	flag.Parse()
+
+	if *imageFlag == "" {
+		log.Fatal("-image is required")
+	}
+	if err := attestSBOM(*imageFlag, *formatFlag); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func formats() []string {
+	var names []string
+	for name := range predicateTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// attestSBOM scans an image in its registry, so that images of other platforms
+// than the host, e.g. Windows images on Linux, are scanned too.
+func attestSBOM(image, format string) error {
+	predicateType, ok := predicateTypes[format]
+	if !ok {
+		return fmt.Errorf("unsupported SBOM format %q, want one of %s", format, strings.Join(formats(), ", "))
+	}
+
+	dir, err := os.MkdirTemp("", "image-sbom-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	sbom := filepath.Join(dir, "sbom.json")
+
+	if err := run("syft", "scan", "registry:"+image, "--output="+format+"="+sbom); err != nil {
+		return err
+	}
+	return run("cosign", append([]string{
+		"attest",
+		"--yes",
+		"--predicate=" + sbom,
+		"--type=" + predicateType,
+	}, append(cosign.KeyArgs(), image)...)...)
+}
+
+// run runs syft or cosign, replaced in tests.
+var run = func(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testImage = "otel/opentelemetry-collector@sha256:0123456789abcdef"
+
+// fakeTools records the syft and cosign calls, failing the ones named in fail.
+// syft writes an SBOM, which cosign is expected to read before it's removed.
+func fakeTools(t *testing.T, fail ...string) *[][]string {
+	t.Helper()
+	var calls [][]string
+	orig := run
+	t.Cleanup(func() { run = orig })
+	run = func(name string, args ...string) error {
+		calls = append(calls, append([]string{name}, args...))
+		if slices.Contains(fail, name) {
+			return errors.New(name + " failed")
+		}
+		switch name {
+		case "syft":
+			_, output, _ := strings.Cut(args[len(args)-1], "=")
+			_, path, _ := strings.Cut(output, "=")
+			return os.WriteFile(path, []byte("{}"), 0o600)
+		case "cosign":
+			for _, arg := range args {
+				if path, ok := strings.CutPrefix(arg, "--predicate="); ok {
+					_, err := os.Stat(path)
+					return err
+				}
+			}
+			return errors.New("no predicate")
+		}
+		return nil
+	}
+	return &calls
+}
+
+func TestAttestSBOM(t *testing.T) {
+	tests := []struct {
+		format        string
+		key           string
+		wantType      string
+		wantSigning   []string
+		wantErrPrefix string
+	}{
+		{format: "spdx-json", wantType: "spdxjson"},
+		{format: "cyclonedx-json", wantType: "cyclonedx"},
+		{format: "spdx-json", key: "/tmp/cosign.key", wantType: "spdxjson", wantSigning: []string{"--key=/tmp/cosign.key", "--tlog-upload=false"}},
+		{format: "syft-json", wantErrPrefix: `unsupported SBOM format "syft-json"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+tt.key, func(t *testing.T) {
+			t.Setenv("COSIGN_KEY", tt.key)
+			calls := fakeTools(t)
+
+			err := attestSBOM(testImage, tt.format)
+			if tt.wantErrPrefix != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErrPrefix) {
+					t.Fatalf("attestSBOM() error = %v, want %s", err, tt.wantErrPrefix)
+				}
+				if len(*calls) != 0 {
+					t.Errorf("ran %q for an unsupported format", *calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(*calls) != 2 {
+				t.Fatalf("calls = %q, want syft then cosign", *calls)
+			}
+
+			syft, attest := (*calls)[0], (*calls)[1]
+			if syft[0] != "syft" || syft[2] != "registry:"+testImage || !strings.HasPrefix(syft[3], "--output="+tt.format+"=") {
+				t.Errorf("syft call = %q, want a %s scan of the registry image", syft, tt.format)
+			}
+			sbom := strings.TrimPrefix(syft[3], "--output="+tt.format+"=")
+			want := append([]string{"cosign", "attest", "--yes", "--predicate=" + sbom, "--type=" + tt.wantType}, append(tt.wantSigning, testImage)...)
+			if !slices.Equal(attest, want) {
+				t.Errorf("cosign call = %q, want %q", attest, want)
+			}
+			if _, err := os.Stat(sbom); !os.IsNotExist(err) {
+				t.Errorf("SBOM %s left behind: %v", sbom, err)
+			}
+		})
+	}
+}
+
+func TestAttestSBOMFailures(t *testing.T) {
+	t.Run("syft", func(t *testing.T) {
+		calls := fakeTools(t, "syft")
+		if err := attestSBOM(testImage, "spdx-json"); err == nil {
+			t.Fatal("attested without an SBOM")
+		}
+		if len(*calls) != 1 {
+			t.Errorf("calls = %q, want syft only", *calls)
+		}
+	})
+	t.Run("cosign", func(t *testing.T) {
+		fakeTools(t, "cosign")
+		if err := attestSBOM(testImage, "spdx-json"); err == nil {
+			t.Fatal("cosign failure ignored")
+		}
+	})
+}
+
+func TestFormats(t *testing.T) {
+	if got, want := formats(), []string{"cyclonedx-json", "spdx-json"}; !slices.Equal(got, want) {
+		t.Errorf("formats() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	coreDistro     = "otelcol"
+	contribDistro  = "otelcol-contrib"
+	otlpDistro     = "otelcol-otlp"
+	templateFolder = "cmd/launchd-generator"
+	distroFolder   = "distributions"
+	scriptsFolder  = "darwin"
+	labelPrefix    = "io.opentelemetry."
+)
+
+var (
+	distFlag = flag.String("d", "", "Collector distributions to build")
+
+	// Linux paths used by the systemd units and their macOS counterparts.
+	pathReplacer = strings.NewReplacer(
+		"/usr/bin/", "/usr/local/bin/",
+		"/etc/", "/usr/local/etc/",
+	)
+)
+
+// service holds the subset of the systemd unit used to render the launchd files.
+type service struct {
+	Label     string
+	Program   string
+	Arguments []string
+	User      string
+	Group     string
+	ConfigDir string
+	KeepAlive bool
+	AddConfig bool
+}
+
+func main() {
+	flag.Parse()
+
+	if len(*distFlag) == 0 {
+		log.Fatal("no distribution to template")
+	}
+	distros := strings.Split(*distFlag, ",")
+
+	for _, distro := range distros {
+		log.Println("Templating launchd service for distribution: " + distro)
+		TemplateDist(distro)
+	}
+}
+
+func TemplateDist(dist string) {
+	switch dist {
+	case coreDistro, contribDistro:
+		templateDist(dist, true)
+	case otlpDistro:
+		templateDist(dist, false)
+	default:
+		log.Println("Unknown distribution: " + dist)
+	}
+}
+
+func templateDist(dist string, addConfig bool) {
+	distDir := filepath.Join(distroFolder, dist)
+	if err := renderDist(dist, distDir, templateFolder, filepath.Join(distDir, scriptsFolder), addConfig); err != nil {
+		panic(err)
+	}
+}
+
+// renderDist renders the launchd files of a distribution from the systemd unit
+// in distDir into outDir.
+func renderDist(dist, distDir, templateDir, outDir string, addConfig bool) error {
+	svc, err := parseService(filepath.Join(distDir, dist+".service"))
+	if err != nil {
+		return err
+	}
+	svc.Label = labelPrefix + dist
+	svc.ConfigDir = pathReplacer.Replace(filepath.Join("/etc", dist))
+	svc.AddConfig = addConfig
+
+	tmpl, err := template.ParseGlob(filepath.Join(templateDir, "*.tmpl"))
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+
+	// pkgbuild runs the preinstall and postinstall scripts found in the scripts
+	// folder; the other files are shipped next to them for postinstall to copy.
+	outputs := map[string]string{
+		"preinstall.tmpl":    "preinstall",
+		"postinstall.tmpl":   "postinstall",
+		"launchd.plist.tmpl": svc.Label + ".plist",
+	}
+	for name, filename := range outputs {
+		f, err := os.OpenFile(filepath.Join(outDir, filename), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+		if err != nil {
+			return err
+		}
+		if err = tmpl.ExecuteTemplate(f, name, svc); err != nil {
+			f.Close()
+			return err
+		}
+		if err = f.Close(); err != nil {
+			return err
+		}
+	}
+
+	if addConfig {
+		cfg, err := os.ReadFile(filepath.Join(distDir, "config.yaml"))
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(filepath.Join(outDir, "config.yaml"), cfg, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseService reads the systemd unit and its EnvironmentFile, expanding the
+// variables referenced by ExecStart.
+func parseService(unitPath string) (*service, error) {
+	unit, err := readKeyValues(unitPath)
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{}
+	if envFile, ok := unit["EnvironmentFile"]; ok {
+		// The EnvironmentFile path is absolute on the target system, the file
+		// itself sits next to the unit in the distribution folder.
+		env, err = readKeyValues(filepath.Join(filepath.Dir(unitPath), filepath.Base(envFile)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	execStart := strings.Fields(os.Expand(unit["ExecStart"], func(key string) string {
+		return strings.Trim(env[key], `"`)
+	}))
+	if len(execStart) == 0 {
+		return nil, fmt.Errorf("%s: missing ExecStart", unitPath)
+	}
+	args := make([]string, 0, len(execStart)-1)
+	for _, arg := range execStart[1:] {
+		args = append(args, pathReplacer.Replace(arg))
+	}
+
+	return &service{
+		Program:   pathReplacer.Replace(execStart[0]),
+		Arguments: args,
+		User:      unit["User"],
+		Group:     unit["Group"],
+		KeepAlive: unit["Restart"] == "on-failure" || unit["Restart"] == "always",
+	}, nil
+}
+
+// readKeyValues reads KEY=VALUE lines, skipping comments and section headers.
+func readKeyValues(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
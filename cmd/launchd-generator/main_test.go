@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Update the golden files in testdata")
+
+func TestRenderDist(t *testing.T) {
+	tests := []struct {
+		dist      string
+		addConfig bool
+	}{
+		{dist: coreDistro, addConfig: true},
+		{dist: otlpDistro, addConfig: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dist, func(t *testing.T) {
+			distDir := filepath.Join("..", "..", distroFolder, tt.dist)
+			outDir := t.TempDir()
+			if err := renderDist(tt.dist, distDir, ".", outDir, tt.addConfig); err != nil {
+				t.Fatal(err)
+			}
+
+			goldenDir := filepath.Join("testdata", tt.dist)
+			for _, name := range []string{"preinstall", "postinstall", labelPrefix + tt.dist + ".plist"} {
+				got, err := os.ReadFile(filepath.Join(outDir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				golden := filepath.Join(goldenDir, name)
+				if *update {
+					if err := os.MkdirAll(goldenDir, 0755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(golden, got, 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != string(want) {
+					t.Errorf("%s differs from %s, run go test -update if intended:\n%s", name, golden, got)
+				}
+			}
+
+			config, err := os.ReadFile(filepath.Join(outDir, "config.yaml"))
+			if !tt.addConfig {
+				if !os.IsNotExist(err) {
+					t.Errorf("config.yaml rendered without addConfig")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join(distDir, "config.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(config) != string(want) {
+				t.Errorf("config.yaml is not the distribution one")
+			}
+		})
+	}
+}
+
+func TestParseServiceMissingExecStart(t *testing.T) {
+	unit := filepath.Join(t.TempDir(), "broken.service")
+	if err := os.WriteFile(unit, []byte("[Service]\nUser=otel\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseService(unit); err == nil {
+		t.Error("expected an error for a unit without ExecStart")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// manifest-sbom converts the manifest.yaml of a distribution into a CycloneDX
+// SBOM listing the collector components built into it. Catalog-based SBOMs of
+// the binary list Go modules, but not which of them are components.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	specVersion = "1.6"
+	// propertyPrefix namespaces the CycloneDX properties of this tool.
+	propertyPrefix = "io.opentelemetry.collector:"
+)
+
+var (
+	manifestFlag = flag.String("manifest", "manifest.yaml", "Path of the distribution manifest")
+	outputFlag   = flag.String("output", "", "Path to write the SBOM to, standard output if empty")
+	versionFlag  = flag.String("version", "", "Version of the distribution, dist.version of the manifest if empty")
+)
+
+// manifest is the subset of the builder manifest describing the components.
+type manifest struct {
+	Dist struct {
+		Module      string `yaml:"module"`
+		Name        string `yaml:"name"`
+		Description string `yaml:"description"`
+		Version     string `yaml:"version"`
+	} `yaml:"dist"`
+	Receivers  []module `yaml:"receivers"`
+	Exporters  []module `yaml:"exporters"`
+	Processors []module `yaml:"processors"`
+	Extensions []module `yaml:"extensions"`
+	Connectors []module `yaml:"connectors"`
+	Providers  []module `yaml:"providers"`
+	Replaces   []string `yaml:"replaces"`
+}
+
+// module is a component entry of the manifest. Import overrides the package
+// holding the component when it is not the module root, e.g. for OBI and the
+// eBPF profiler.
+type module struct {
+	GoMod  string `yaml:"gomod"`
+	Import string `yaml:"import"`
+}
+
+type bom struct {
+	BOMFormat    string       `json:"bomFormat"`
+	SpecVersion  string       `json:"specVersion"`
+	Version      int          `json:"version"`
+	Metadata     metadata     `json:"metadata"`
+	Components   []component  `json:"components"`
+	Dependencies []dependency `json:"dependencies"`
+}
+
+type metadata struct {
+	Component component `json:"component"`
+}
+
+type component struct {
+	BOMRef      string     `json:"bom-ref"`
+	Type        string     `json:"type"`
+	Name        string     `json:"name"`
+	Version     string     `json:"version,omitempty"`
+	Description string     `json:"description,omitempty"`
+	PURL        string     `json:"purl,omitempty"`
+	Properties  []property `json:"properties,omitempty"`
+}
+
+type property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func main() {
+	flag.Parse()
+
+	data, err := os.ReadFile(*manifestFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var m manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		log.Fatalf("parsing %s: %v", *manifestFlag, err)
+	}
+	if *versionFlag != "" {
+		m.Dist.Version = *versionFlag
+	}
+
+	sbom, err := newBOM(m)
+	if err != nil {
+		log.Fatalf("%s: %v", *manifestFlag, err)
+	}
+	out, err := json.MarshalIndent(sbom, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	out = append(out, '\n')
+
+	if *outputFlag == "" {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(*outputFlag), 0o755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*outputFlag, out, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newBOM returns the component SBOM of a manifest. Components whose module is
+// replaced by another module version are reported as the replacement, local
+// replacements keep the manifest version.
+func newBOM(m manifest) (bom, error) {
+	replaces, err := parseReplaces(m.Replaces)
+	if err != nil {
+		return bom{}, err
+	}
+
+	rootPURL := "pkg:golang/" + m.Dist.Module + "@" + m.Dist.Version
+	root := component{
+		BOMRef:      rootPURL,
+		Type:        "application",
+		Name:        m.Dist.Name,
+		Version:     m.Dist.Version,
+		Description: m.Dist.Description,
+		PURL:        rootPURL,
+	}
+	b := bom{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: specVersion,
+		Version:     1,
+		Metadata:    metadata{Component: root},
+		Components:  []component{},
+	}
+	rootDeps := dependency{Ref: root.BOMRef, DependsOn: []string{}}
+
+	for _, kind := range []struct {
+		name    string
+		modules []module
+	}{
+		{"receiver", m.Receivers},
+		{"exporter", m.Exporters},
+		{"processor", m.Processors},
+		{"extension", m.Extensions},
+		{"connector", m.Connectors},
+		{"provider", m.Providers},
+	} {
+		for _, mod := range kind.modules {
+			c, err := newComponent(kind.name, mod, replaces)
+			if err != nil {
+				return bom{}, err
+			}
+			b.Components = append(b.Components, c)
+			rootDeps.DependsOn = append(rootDeps.DependsOn, c.BOMRef)
+		}
+	}
+	b.Dependencies = []dependency{rootDeps}
+	return b, nil
+}
+
+func newComponent(kind string, mod module, replaces map[string]string) (component, error) {
+	path, version, ok := strings.Cut(strings.TrimSpace(mod.GoMod), " ")
+	if !ok {
+		return component{}, fmt.Errorf("gomod %q has no version", mod.GoMod)
+	}
+	version = strings.TrimSpace(version)
+
+	name := path
+	properties := []property{{Name: propertyPrefix + "component:type", Value: kind}}
+	if mod.Import != "" {
+		name = mod.Import
+		properties = append(properties, property{Name: propertyPrefix + "component:import", Value: mod.Import})
+	}
+
+	if replacement, ok := replaces[path]; ok {
+		properties = append(properties, property{Name: propertyPrefix + "module:replace", Value: replacement})
+		if rPath, rVersion, ok := strings.Cut(replacement, " "); ok {
+			path, version = rPath, rVersion
+		}
+	}
+
+	purl := "pkg:golang/" + path + "@" + version
+	if subpath, ok := strings.CutPrefix(mod.Import, path+"/"); ok {
+		purl += "#" + subpath
+	}
+	return component{
+		BOMRef:     purl,
+		Type:       "library",
+		Name:       name,
+		Version:    version,
+		PURL:       purl,
+		Properties: properties,
+	}, nil
+}
+
+// parseReplaces maps the replaced module paths of the manifest to their
+// replacement, "path version" for modules and a file path for local copies.
+func parseReplaces(replaces []string) (map[string]string, error) {
+	m := make(map[string]string, len(replaces))
+	for _, r := range replaces {
+		old, replacement, ok := strings.Cut(r, "=>")
+		if !ok {
+			return nil, fmt.Errorf("invalid replace %q", r)
+		}
+		oldPath, _, _ := strings.Cut(strings.TrimSpace(old), " ")
+		m[oldPath] = strings.Join(strings.Fields(replacement), " ")
+	}
+	return m, nil
+}
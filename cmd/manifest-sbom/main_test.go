@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const testManifest = `
+dist:
+  module: github.com/open-telemetry/opentelemetry-collector-releases/contrib
+  name: otelcol-contrib
+  description: OpenTelemetry Collector Contrib
+  version: 0.150.0
+receivers:
+  - gomod: go.opentelemetry.io/collector/receiver/otlpreceiver v0.150.0
+  - gomod: go.opentelemetry.io/obi v0.11.0
+    import: go.opentelemetry.io/obi/collector
+exporters:
+  - gomod: github.com/open-telemetry/opentelemetry-collector-contrib/exporter/kafkaexporter v0.150.0
+providers:
+  - gomod: go.opentelemetry.io/collector/confmap/provider/envprovider v1.30.0
+replaces:
+  - go.opentelemetry.io/obi => ../../../internal/obi-src
+  - github.com/open-telemetry/opentelemetry-collector-contrib/exporter/kafkaexporter => github.com/example/kafkaexporter v0.150.1
+`
+
+func TestNewBOM(t *testing.T) {
+	var m manifest
+	if err := yaml.Unmarshal([]byte(testManifest), &m); err != nil {
+		t.Fatal(err)
+	}
+	b, err := newBOM(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := b.Metadata.Component.PURL, "pkg:golang/github.com/open-telemetry/opentelemetry-collector-releases/contrib@0.150.0"; got != want {
+		t.Errorf("root purl = %q, want %q", got, want)
+	}
+
+	want := []struct {
+		name, purl, kind, replace string
+	}{
+		{"go.opentelemetry.io/collector/receiver/otlpreceiver", "pkg:golang/go.opentelemetry.io/collector/receiver/otlpreceiver@v0.150.0", "receiver", ""},
+		{"go.opentelemetry.io/obi/collector", "pkg:golang/go.opentelemetry.io/obi@v0.11.0#collector", "receiver", "../../../internal/obi-src"},
+		{"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/kafkaexporter", "pkg:golang/github.com/example/kafkaexporter@v0.150.1", "exporter", "github.com/example/kafkaexporter v0.150.1"},
+		{"go.opentelemetry.io/collector/confmap/provider/envprovider", "pkg:golang/go.opentelemetry.io/collector/confmap/provider/envprovider@v1.30.0", "provider", ""},
+	}
+	if len(b.Components) != len(want) {
+		t.Fatalf("got %d components, want %d", len(b.Components), len(want))
+	}
+	for i, w := range want {
+		c := b.Components[i]
+		if c.Name != w.name || c.PURL != w.purl {
+			t.Errorf("component %d = %s %s, want %s %s", i, c.Name, c.PURL, w.name, w.purl)
+		}
+		if got := propertyValue(c, "component:type"); got != w.kind {
+			t.Errorf("%s: component type = %q, want %q", c.Name, got, w.kind)
+		}
+		if got := propertyValue(c, "module:replace"); got != w.replace {
+			t.Errorf("%s: replace = %q, want %q", c.Name, got, w.replace)
+		}
+	}
+
+	if len(b.Dependencies) != 1 || len(b.Dependencies[0].DependsOn) != len(want) {
+		t.Errorf("dependencies = %+v, want the root depending on every component", b.Dependencies)
+	}
+}
+
+func TestNewBOMDistributions(t *testing.T) {
+	manifests, err := filepath.Glob("../../distributions/*/manifest.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range manifests {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m manifest
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		b, err := newBOM(m)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		refs := map[string]bool{}
+		for _, c := range b.Components {
+			if refs[c.BOMRef] {
+				t.Errorf("%s: duplicate bom-ref %s", path, c.BOMRef)
+			}
+			refs[c.BOMRef] = true
+		}
+	}
+}
+
+func propertyValue(c component, name string) string {
+	for _, p := range c.Properties {
+		if p.Name == propertyPrefix+name {
+			return p.Value
+		}
+	}
+	return ""
+}
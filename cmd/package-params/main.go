@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// package-params passes the service arguments of the Chocolatey and winget
+// packages generated by goreleaser to the MSI, as goreleaser can't customize
+// their install script and installer switches. It is run on the goreleaser
+// dist folder after a release:
+//
+//   - the chocolateyinstall.ps1 of every .nupkg maps the /SvcArgs package
+//     parameter, e.g. choco install otelcol --params "'/SvcArgs:--config C:\otel.yaml'",
+//     to the MSI property;
+//   - every winget installer manifest declares the MSI property as a custom
+//     installer switch, overridden with winget install --custom.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	chocolateyInstallScript = "tools/chocolateyinstall.ps1"
+	// svcArgsMarker identifies install scripts already mapping /SvcArgs.
+	svcArgsMarker = "# package-params: /SvcArgs"
+)
+
+var (
+	distFlag     = flag.String("dist", "dist", "goreleaser dist folder holding the .nupkg files and winget manifests")
+	propertyFlag = flag.String("property", "COLLECTOR_SVC_ARGS", "MSI property holding the service arguments")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := run(*distFlag, *propertyFlag); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(dist, property string) error {
+	return filepath.WalkDir(dist, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch {
+		case strings.HasSuffix(path, ".nupkg"):
+			log.Printf("Mapping /SvcArgs to %s in %s", property, path)
+			return patchNupkg(path, property)
+		case strings.HasSuffix(path, ".installer.yaml"):
+			log.Printf("Adding the %s installer switch to %s", property, path)
+			return patchWingetInstaller(path, property)
+		}
+		return nil
+	})
+}
+
+// svcArgsScript returns the PowerShell prepended to chocolateyinstall.ps1.
+// Install-ChocolateyPackage appends chocolateyInstallArguments to the msiexec
+// arguments, so the generated install step is left untouched.
+func svcArgsScript(property string) string {
+	return svcArgsMarker + "\n" +
+		"$packageParameters = Get-PackageParameters\n" +
+		"if ($packageParameters['SvcArgs']) {\n" +
+		"  $svcArgs = $packageParameters['SvcArgs'] -replace '\"', '\"\"'\n" +
+		"  $env:chocolateyInstallArguments = \"$env:chocolateyInstallArguments " + property + "=`\"$svcArgs`\"\"\n" +
+		"}\n\n"
+}
+
+// patchNupkg rewrites the package with the install script mapping /SvcArgs,
+// keeping the other entries as they are.
+func patchNupkg(path, property string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	found := false
+	for _, f := range r.File {
+		if !strings.EqualFold(f.Name, chocolateyInstallScript) {
+			if err := w.Copy(f); err != nil {
+				return err
+			}
+			continue
+		}
+		found = true
+		script, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(script, []byte(svcArgsMarker)) {
+			script = append([]byte(svcArgsScript(property)), script...)
+		}
+		header := f.FileHeader
+		fw, err := w.CreateHeader(&header)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(script); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s: no %s", path, chocolateyInstallScript)
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := r.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// patchWingetInstaller sets InstallerSwitches.Custom of an installer manifest
+// to an empty value of the MSI property: the installer keeps its default
+// service arguments, and the property is documented as the one to override.
+// winget passes the --custom arguments after it, and msiexec keeps the last
+// value of a property.
+func patchWingetInstaller(path, property string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: not a winget manifest", path)
+	}
+	root := doc.Content[0]
+
+	switches := mappingValue(root, "InstallerSwitches")
+	if switches == nil {
+		switches = &yaml.Node{Kind: yaml.MappingNode}
+		root.Content = append(root.Content, scalar("InstallerSwitches"), switches)
+	}
+	custom := property + `=""`
+	if value := mappingValue(switches, "Custom"); value != nil {
+		if strings.Contains(value.Value, property+"=") {
+			return nil
+		}
+		value.Value = strings.TrimSpace(value.Value + " " + custom)
+	} else {
+		switches.Content = append(switches.Content, scalar("Custom"), scalar(custom))
+	}
+
+	var out bytes.Buffer
+	e := yaml.NewEncoder(&out)
+	e.SetIndent(2)
+	if err := e.Encode(&doc); err != nil {
+		return err
+	}
+	if err := e.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out.Bytes(), 0o644)
+}
+
+// mappingValue returns the value of a key of a mapping node, nil if missing.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const installScript = "$ErrorActionPreference = 'Stop'\nInstall-ChocolateyPackage @packageArgs\n"
+
+func writeNupkg(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"otelcol.nuspec":                "<package/>",
+		"tools/chocolateyinstall.ps1":   installScript,
+		"tools/chocolateyuninstall.ps1": "Uninstall-ChocolateyPackage",
+	} {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readNupkg(t *testing.T, path string) map[string]string {
+	t.Helper()
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	files := map[string]string{}
+	for _, f := range r.File {
+		content, err := readZipFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestRun(t *testing.T) {
+	dist := t.TempDir()
+	nupkg := filepath.Join(dist, "otelcol.0.160.0.nupkg")
+	writeNupkg(t, nupkg)
+	installer := filepath.Join(dist, "winget", "manifests", "o", "OpenTelemetry", "otelcol", "0.160.0", "OpenTelemetry.otelcol.installer.yaml")
+	if err := os.MkdirAll(filepath.Dir(installer), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "# yaml-language-server: $schema=https://aka.ms/winget-manifest.installer.1.6.0.schema.json\n" +
+		"PackageIdentifier: OpenTelemetry.otelcol\nPackageVersion: 0.160.0\nInstallerType: msi\nManifestType: installer\n"
+	if err := os.WriteFile(installer, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Running twice leaves the packages as patched once.
+	for range 2 {
+		if err := run(dist, "COLLECTOR_SVC_ARGS"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := readNupkg(t, nupkg)
+	want := svcArgsScript("COLLECTOR_SVC_ARGS") + installScript
+	if got := files["tools/chocolateyinstall.ps1"]; got != want {
+		t.Errorf("chocolateyinstall.ps1:\n%s\nwant:\n%s", got, want)
+	}
+	if got := files["otelcol.nuspec"]; got != "<package/>" {
+		t.Errorf("otelcol.nuspec changed to %q", got)
+	}
+	if len(files) != 3 {
+		t.Errorf("got %d package files, want 3", len(files))
+	}
+
+	data, err := os.ReadFile(installer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if !strings.HasPrefix(got, "# yaml-language-server:") {
+		t.Errorf("schema comment dropped:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "ManifestType: installer\nInstallerSwitches:\n  Custom: COLLECTOR_SVC_ARGS=\"\"\n") {
+		t.Errorf("unexpected installer manifest:\n%s", got)
+	}
+}
+
+func TestRunWithoutInstallScript(t *testing.T) {
+	dist := t.TempDir()
+	f, err := os.Create(filepath.Join(dist, "otelcol.nupkg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zip.NewWriter(f).Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if err := run(dist, "COLLECTOR_SVC_ARGS"); err == nil {
+		t.Error("expected an error for a package without install script")
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const aptComponent = "main"
+
+// aptRepository describes the static apt repository written by writeApt.
+type aptRepository struct {
+	origin      string
+	label       string
+	suite       string
+	description string
+	date        time.Time
+}
+
+// aptEntry is a deb package of the pool.
+type aptEntry struct {
+	pkg debPackage
+	// filename is the path of the package relative to the repository root.
+	filename string
+	size     int64
+	md5      string
+	sha1     string
+	sha256   string
+}
+
+// writeApt writes the Packages indexes and the Release file of the debs in
+// the pool of dir, laid out as
+//
+//	pool/main/<package>/<file>.deb
+//	dists/<suite>/main/binary-<arch>/Packages{,.gz}
+//	dists/<suite>/Release
+//
+// and returns the path of the Release file.
+func writeApt(dir string, repo aptRepository) (string, error) {
+	debs, err := filepath.Glob(filepath.Join(dir, "pool", aptComponent, "*", "*.deb"))
+	if err != nil {
+		return "", err
+	}
+	if len(debs) == 0 {
+		return "", fmt.Errorf("no deb packages in %s", dir)
+	}
+
+	byArch := map[string][]aptEntry{}
+	for _, deb := range debs {
+		entry, err := newAptEntry(dir, deb)
+		if err != nil {
+			return "", err
+		}
+		arch := entry.pkg.field("Architecture")
+		byArch[arch] = append(byArch[arch], entry)
+	}
+
+	distDir := filepath.Join(dir, "dists", repo.suite)
+	var indexes []string
+	var archs []string
+	for arch, entries := range byArch {
+		archs = append(archs, arch)
+		sort.Slice(entries, func(i, j int) bool { return entries[i].filename < entries[j].filename })
+
+		var packages bytes.Buffer
+		for _, e := range entries {
+			e.write(&packages)
+		}
+		index := path.Join(aptComponent, "binary-"+arch, "Packages")
+		if err := writeFile(filepath.Join(distDir, index), packages.Bytes()); err != nil {
+			return "", err
+		}
+		gz, err := gzipBytes(packages.Bytes())
+		if err != nil {
+			return "", err
+		}
+		if err := writeFile(filepath.Join(distDir, index+".gz"), gz); err != nil {
+			return "", err
+		}
+		indexes = append(indexes, index, index+".gz")
+	}
+	sort.Strings(archs)
+	sort.Strings(indexes)
+
+	release, err := repo.release(distDir, archs, indexes)
+	if err != nil {
+		return "", err
+	}
+	releasePath := filepath.Join(distDir, "Release")
+	return releasePath, writeFile(releasePath, release)
+}
+
+func newAptEntry(dir, deb string) (aptEntry, error) {
+	var pkg debPackage
+	md5Sum, sha1Sum, sha256Sum := md5.New(), sha1.New(), sha256.New()
+	size, err := hashPackage(deb, func(r io.Reader) (err error) {
+		pkg, err = parseDeb(r)
+		return err
+	}, md5Sum, sha1Sum, sha256Sum)
+	if err != nil {
+		return aptEntry{}, err
+	}
+	rel, err := filepath.Rel(dir, deb)
+	if err != nil {
+		return aptEntry{}, err
+	}
+	return aptEntry{
+		pkg:      pkg,
+		filename: filepath.ToSlash(rel),
+		size:     size,
+		md5:      hex.EncodeToString(md5Sum.Sum(nil)),
+		sha1:     hex.EncodeToString(sha1Sum.Sum(nil)),
+		sha256:   hex.EncodeToString(sha256Sum.Sum(nil)),
+	}, nil
+}
+
+// write writes the Packages paragraph of the entry, the control fields
+// followed by the location and checksums of the package.
+func (e aptEntry) write(b *bytes.Buffer) {
+	for _, f := range e.pkg.fields {
+		fmt.Fprintf(b, "%s: %s\n", f.name, f.value)
+	}
+	fmt.Fprintf(b, "Filename: %s\n", e.filename)
+	fmt.Fprintf(b, "Size: %d\n", e.size)
+	fmt.Fprintf(b, "MD5sum: %s\n", e.md5)
+	fmt.Fprintf(b, "SHA1: %s\n", e.sha1)
+	fmt.Fprintf(b, "SHA256: %s\n", e.sha256)
+	b.WriteString("\n")
+}
+
+// release returns the Release file listing the indexes with their checksums.
+func (repo aptRepository) release(distDir string, archs, indexes []string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Origin: %s\n", repo.origin)
+	fmt.Fprintf(&b, "Label: %s\n", repo.label)
+	fmt.Fprintf(&b, "Suite: %s\n", repo.suite)
+	fmt.Fprintf(&b, "Codename: %s\n", repo.suite)
+	fmt.Fprintf(&b, "Date: %s\n", repo.date.UTC().Format(time.RFC1123))
+	fmt.Fprintf(&b, "Architectures: %s\n", strings.Join(archs, " "))
+	fmt.Fprintf(&b, "Components: %s\n", aptComponent)
+	fmt.Fprintf(&b, "Description: %s\n", repo.description)
+
+	for _, sum := range []struct {
+		name string
+		hash func() hash.Hash
+	}{
+		{"MD5Sum", md5.New},
+		{"SHA1", sha1.New},
+		{"SHA256", sha256.New},
+	} {
+		fmt.Fprintf(&b, "%s:\n", sum.name)
+		for _, index := range indexes {
+			data, err := os.ReadFile(filepath.Join(distDir, index))
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&b, " %s %d %s\n", hexSum(sum.hash(), data), len(data), index)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+func hexSum(h hash.Hash, data []byte) string {
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
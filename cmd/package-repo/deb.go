@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const arMagic = "!<arch>\n"
+
+// debPackage is the control paragraph of a deb package.
+type debPackage struct {
+	// fields are the control fields in their original order.
+	fields []controlField
+}
+
+type controlField struct {
+	name  string
+	value string
+}
+
+func (p debPackage) field(name string) string {
+	for _, f := range p.fields {
+		if strings.EqualFold(f.name, name) {
+			return f.value
+		}
+	}
+	return ""
+}
+
+// readDeb reads the control file of a deb package.
+func readDeb(path string) (debPackage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return debPackage{}, err
+	}
+	defer f.Close()
+
+	pkg, err := parseDeb(f)
+	if err != nil {
+		return debPackage{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return pkg, nil
+}
+
+// parseDeb reads the control file of a deb package, an ar archive holding a
+// control.tar or control.tar.gz member. It stops reading after the control
+// member.
+func parseDeb(in io.Reader) (debPackage, error) {
+	r := bufio.NewReader(in)
+	magic := make([]byte, len(arMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != arMagic {
+		return debPackage{}, errors.New("not a deb package")
+	}
+
+	header := make([]byte, 60)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return debPackage{}, errors.New("no control archive")
+			}
+			return debPackage{}, err
+		}
+		name := strings.TrimSuffix(strings.TrimSpace(string(header[0:16])), "/")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil {
+			return debPackage{}, fmt.Errorf("invalid ar member size: %w", err)
+		}
+		member := io.LimitReader(r, size)
+
+		switch name {
+		case "control.tar", "control.tar.gz":
+			var tr io.Reader = member
+			if name == "control.tar.gz" {
+				gz, err := gzip.NewReader(member)
+				if err != nil {
+					return debPackage{}, err
+				}
+				defer gz.Close()
+				tr = gz
+			}
+			control, err := readControl(tar.NewReader(tr))
+			if err != nil {
+				return debPackage{}, err
+			}
+			return parseControl(control)
+		case "control.tar.xz", "control.tar.zst":
+			return debPackage{}, fmt.Errorf("unsupported %s, packages must use gzip compression", name)
+		}
+
+		// Members are padded to an even size.
+		if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+			return debPackage{}, err
+		}
+	}
+}
+
+func readControl(tr *tar.Reader) ([]byte, error) {
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("no control file in control archive")
+			}
+			return nil, err
+		}
+		if strings.TrimPrefix(hdr.Name, "./") == "control" {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// parseControl parses a control paragraph. Continuation lines, starting with
+// a space or tab, are kept as part of the field value.
+func parseControl(data []byte) (debPackage, error) {
+	var p debPackage
+	for _, line := range strings.Split(strings.TrimRight(string(bytes.TrimSpace(data)), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(p.fields) == 0 {
+				return debPackage{}, fmt.Errorf("invalid control file: continuation line without field")
+			}
+			p.fields[len(p.fields)-1].value += "\n" + line
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return debPackage{}, fmt.Errorf("invalid control line %q", line)
+		}
+		p.fields = append(p.fields, controlField{name: name, value: strings.TrimSpace(value)})
+	}
+	for _, required := range []string{"Package", "Version", "Architecture"} {
+		if p.field(required) == "" {
+			return debPackage{}, fmt.Errorf("control file has no %s", required)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// passphraseEnv holds the passphrase of the signing key, shared with the
+// nfpm package signatures.
+const passphraseEnv = "NFPM_PASSPHRASE"
+
+// signer signs the repository metadata with gpg.
+type signer struct {
+	// home is the GNUPGHOME the key file was imported into, empty to use the
+	// default keyring.
+	home  string
+	keyID string
+}
+
+// newSigner returns a signer for the armored private key file, imported into
+// a temporary keyring, or for a key of the default keyring.
+func newSigner(keyFile, keyID string) (*signer, error) {
+	s := &signer{keyID: keyID}
+	if keyFile == "" {
+		return s, nil
+	}
+	home, err := os.MkdirTemp("", "package-repo-gnupg")
+	if err != nil {
+		return nil, err
+	}
+	s.home = home
+	if _, err := s.gpg(nil, "--import", keyFile); err != nil {
+		s.close()
+		return nil, fmt.Errorf("importing %s: %w", keyFile, err)
+	}
+	return s, nil
+}
+
+func (s *signer) close() {
+	if s.home != "" {
+		os.RemoveAll(s.home)
+	}
+}
+
+// clearSign writes the inline signed copy of path, e.g. InRelease.
+func (s *signer) clearSign(path, output string) error {
+	_, err := s.sign("--clearsign", "--output="+output, path)
+	return err
+}
+
+// detachSign writes the armored detached signature of path, e.g. Release.gpg.
+func (s *signer) detachSign(path, output string) error {
+	_, err := s.sign("--detach-sign", "--armor", "--output="+output, path)
+	return err
+}
+
+// publicKey returns the armored public key verifying the signatures.
+func (s *signer) publicKey() ([]byte, error) {
+	args := []string{"--export", "--armor"}
+	if s.keyID != "" {
+		args = append(args, s.keyID)
+	}
+	key, err := s.gpg(nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(key)) == 0 {
+		return nil, fmt.Errorf("no public key to export")
+	}
+	return key, nil
+}
+
+func (s *signer) sign(args ...string) ([]byte, error) {
+	// Existing outputs would make gpg prompt for overwriting.
+	args = append([]string{"--yes", "--digest-algo=SHA256"}, args...)
+	if s.keyID != "" {
+		args = append([]string{"--local-user=" + s.keyID}, args...)
+	}
+	var stdin []byte
+	if passphrase, ok := os.LookupEnv(passphraseEnv); ok {
+		args = append([]string{"--pinentry-mode=loopback", "--passphrase-fd=0"}, args...)
+		stdin = []byte(passphrase)
+	}
+	return s.gpg(stdin, args...)
+}
+
+func (s *signer) gpg(stdin []byte, args ...string) ([]byte, error) {
+	args = append([]string{"--batch"}, args...)
+	cmd := exec.Command("gpg", args...)
+	if s.home != "" {
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+s.home)
+	}
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gpg %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
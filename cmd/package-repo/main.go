@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// package-repo turns the deb and rpm packages of goreleaser dist folders into
+// static apt and yum repositories, signed with gpg, that can be served from
+// any static host:
+//
+//	<out>/gpg.key
+//	<out>/apt/pool/main/<package>/*.deb
+//	<out>/apt/dists/<suite>/{Release,Release.gpg,InRelease}
+//	<out>/apt/dists/<suite>/main/binary-<arch>/Packages{,.gz}
+//	<out>/yum/Packages/*.rpm
+//	<out>/yum/repodata/{repomd.xml,repomd.xml.asc,*.xml.gz}
+//
+// Packages already in the output are kept, so that the repositories
+// accumulate releases when the output is synced from and back to the host.
+//
+// The metadata is written here rather than with apt-ftparchive and
+// createrepo_c so that it only depends on Go and gpg on any runner, and is
+// reproducible from SOURCE_DATE_EPOCH, where both tools stamp the current time
+// into the metadata by default. The tests read the repositories back with apt
+// and dnf when they are installed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	outFlag         = flag.String("out", "_repo", "Directory to write the repositories to")
+	suiteFlag       = flag.String("suite", "stable", "Suite of the apt repository")
+	originFlag      = flag.String("origin", "OpenTelemetry", "Origin of the apt repository")
+	labelFlag       = flag.String("label", "OpenTelemetry Collector", "Label of the apt repository")
+	descriptionFlag = flag.String("description", "OpenTelemetry Collector distributions", "Description of the apt repository")
+	keyFileFlag     = flag.String("key-file", "", "Armored private gpg key to sign with, passphrase in "+passphraseEnv)
+	keyIDFlag       = flag.String("key-id", "", "ID of the gpg key to sign with, the default key if empty")
+	unsignedFlag    = flag.Bool("unsigned", false, "Don't sign the repositories, for local testing")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <dist dir>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dists := flag.Args()
+	if len(dists) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(dists); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(dists []string) error {
+	aptDir := filepath.Join(*outFlag, "apt")
+	yumDir := filepath.Join(*outFlag, "yum")
+	debs, rpms, err := collect(dists, aptDir, yumDir)
+	if err != nil {
+		return err
+	}
+	log.Printf("added %d deb and %d rpm packages", debs, rpms)
+
+	date, err := sourceDate()
+	if err != nil {
+		return err
+	}
+	release, err := writeApt(aptDir, aptRepository{
+		origin:      *originFlag,
+		label:       *labelFlag,
+		suite:       *suiteFlag,
+		description: *descriptionFlag,
+		date:        date,
+	})
+	if err != nil {
+		return err
+	}
+	repomd, err := writeYum(yumDir, date)
+	if err != nil {
+		return err
+	}
+
+	if *unsignedFlag {
+		return nil
+	}
+	s, err := newSigner(*keyFileFlag, *keyIDFlag)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	distDir := filepath.Dir(release)
+	if err := s.clearSign(release, filepath.Join(distDir, "InRelease")); err != nil {
+		return err
+	}
+	if err := s.detachSign(release, filepath.Join(distDir, "Release.gpg")); err != nil {
+		return err
+	}
+	if err := s.detachSign(repomd, repomd+".asc"); err != nil {
+		return err
+	}
+	key, err := s.publicKey()
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(*outFlag, "gpg.key"), key)
+}
+
+// collect copies the debs of the dist dirs into the apt pool and the rpms
+// into the yum Packages directory and returns how many were copied. AIX rpms
+// can't be installed with yum or dnf and are left out.
+func collect(dists []string, aptDir, yumDir string) (debs, rpms int, err error) {
+	for _, dist := range dists {
+		paths, err := filepath.Glob(filepath.Join(dist, "*"))
+		if err != nil {
+			return 0, 0, err
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			switch filepath.Ext(path) {
+			case ".deb":
+				pkg, err := readDeb(path)
+				if err != nil {
+					return 0, 0, err
+				}
+				dst := filepath.Join(aptDir, "pool", aptComponent, pkg.field("Package"), filepath.Base(path))
+				if err := copyFile(path, dst); err != nil {
+					return 0, 0, err
+				}
+				debs++
+			case ".rpm":
+				pkg, err := readRPM(path)
+				if err != nil {
+					return 0, 0, err
+				}
+				if strings.EqualFold(pkg.header.stringValue(tagOS), "aix") {
+					continue
+				}
+				if err := copyFile(path, filepath.Join(yumDir, "Packages", filepath.Base(path))); err != nil {
+					return 0, 0, err
+				}
+				rpms++
+			}
+		}
+	}
+	return debs, rpms, nil
+}
+
+// sourceDate returns SOURCE_DATE_EPOCH if set, for reproducible metadata, and
+// the current time otherwise.
+func sourceDate() (time.Time, error) {
+	epoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok {
+		return time.Now().UTC(), nil
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH: %w", err)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+// hashPackage parses the header of a package with parse while streaming the
+// whole file through the hashes, so that packages are never held in memory.
+// It returns the size of the package.
+func hashPackage(path string, parse func(io.Reader) error, hashes ...hash.Hash) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var size byteCounter
+	writers := []io.Writer{&size}
+	for _, h := range hashes {
+		writers = append(writers, h)
+	}
+	r := io.TeeReader(f, io.MultiWriter(writers...))
+	if err := parse(r); err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	// Hash the rest of the package, past its header.
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return int64(size), nil
+}
+
+// byteCounter counts the bytes written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,448 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testControl = `Package: otelcol
+Version: 0.150.0
+Architecture: amd64
+Maintainer: The OpenTelemetry Collector maintainers
+Description: OpenTelemetry Collector
+ Core distribution.
+`
+
+// buildDeb writes a deb package with the control file and no data.
+func buildDeb(t *testing.T, path, control string) {
+	t.Helper()
+	var controlTar bytes.Buffer
+	gz := gzip.NewWriter(&controlTar)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "./control", Mode: 0o644, Size: int64(len(control))}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte(control))
+	tw.Close()
+	gz.Close()
+
+	var deb bytes.Buffer
+	deb.WriteString(arMagic)
+	for _, m := range []struct {
+		name string
+		data []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", controlTar.Bytes()},
+		{"data.tar.gz", nil},
+	} {
+		fmt.Fprintf(&deb, "%-16s%-12d%-6d%-6d%-8s%-10d`\n", m.name, 0, 0, 0, "100644", len(m.data))
+		deb.Write(m.data)
+		if len(m.data)%2 == 1 {
+			deb.WriteByte('\n')
+		}
+	}
+	if err := os.WriteFile(path, deb.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testTag struct {
+	tag    int32
+	typ    int32
+	values any
+}
+
+// rpmHeaderBytes encodes a header structure.
+func rpmHeaderBytes(tags []testTag) []byte {
+	var index, store bytes.Buffer
+	for _, tag := range tags {
+		var count int
+		if tag.typ == typeInt32 {
+			for store.Len()%4 != 0 {
+				store.WriteByte(0)
+			}
+		}
+		offset := store.Len()
+		switch v := tag.values.(type) {
+		case string:
+			store.WriteString(v + "\x00")
+			count = 1
+		case []string:
+			for _, s := range v {
+				store.WriteString(s + "\x00")
+			}
+			count = len(v)
+		case []int32:
+			binary.Write(&store, binary.BigEndian, v)
+			count = len(v)
+		}
+		binary.Write(&index, binary.BigEndian, []int32{tag.tag, tag.typ, int32(offset), int32(count)})
+	}
+	var h bytes.Buffer
+	h.WriteString(rpmHeaderMagic)
+	h.Write(make([]byte, 4))
+	binary.Write(&h, binary.BigEndian, []uint32{uint32(len(tags)), uint32(store.Len())})
+	h.Write(index.Bytes())
+	h.Write(store.Bytes())
+	return h.Bytes()
+}
+
+// buildRPM writes an rpm package with an empty signature header and no payload.
+func buildRPM(t *testing.T, path, goos string) {
+	t.Helper()
+	lead := make([]byte, rpmLeadSize)
+	copy(lead, rpmLeadMagic)
+	signature := rpmHeaderBytes([]testTag{{1000, typeInt32, []int32{0}}})
+	header := rpmHeaderBytes([]testTag{
+		{tagName, typeString, "otelcol"},
+		{tagVersion, typeString, "0.150.0"},
+		{tagRelease, typeString, "1"},
+		{tagSummary, typeI18NString, []string{"OpenTelemetry Collector"}},
+		{tagLicense, typeString, "Apache 2.0"},
+		{tagOS, typeString, goos},
+		{tagArch, typeString, "x86_64"},
+		{tagProvideName, typeStringArray, []string{"otelcol"}},
+		{tagProvideFlags, typeInt32, []int32{senseEqual}},
+		{tagProvideVersion, typeStringArray, []string{"0.150.0-1"}},
+		{tagRequireName, typeStringArray, []string{"/bin/sh", "rpmlib(CompressedFileNames)"}},
+		{tagRequireFlags, typeInt32, []int32{0, senseRPMLib | senseLess | senseEqual}},
+		{tagRequireVersion, typeStringArray, []string{"", "3.0.4-1"}},
+		{tagDirIndexes, typeInt32, []int32{0, 1}},
+		{tagBaseNames, typeStringArray, []string{"otelcol", "config.yaml"}},
+		{tagDirNames, typeStringArray, []string{"/usr/bin/", "/etc/otelcol/"}},
+	})
+	var rpm bytes.Buffer
+	rpm.Write(lead)
+	rpm.Write(signature)
+	rpm.Write(make([]byte, (8-len(signature)%8)%8))
+	rpm.Write(header)
+	if err := os.WriteFile(path, rpm.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadDeb(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otelcol_0.150.0_linux_amd64.deb")
+	buildDeb(t, path, testControl)
+
+	pkg, err := readDeb(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"Package":      "otelcol",
+		"architecture": "amd64",
+		"Description":  "OpenTelemetry Collector\n Core distribution.",
+	} {
+		if got := pkg.field(name); got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+
+	buildDeb(t, path, "Package: otelcol\n")
+	if _, err := readDeb(path); err == nil {
+		t.Error("expected an error for a control file without version")
+	}
+}
+
+func TestReadRPM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otelcol_0.150.0_linux_amd64.rpm")
+	buildRPM(t, path, "linux")
+
+	pkg, err := readRPM(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := pkg.header
+	if got := h.stringValue(tagName); got != "otelcol" {
+		t.Errorf("name: got %q", got)
+	}
+	if got := h.stringValue(tagSummary); got != "OpenTelemetry Collector" {
+		t.Errorf("summary: got %q", got)
+	}
+	paths, _ := h.files()
+	if strings.Join(paths, ",") != "/usr/bin/otelcol,/etc/otelcol/config.yaml" {
+		t.Errorf("files: got %v", paths)
+	}
+	requires := dependencies(h, tagRequireName, tagRequireFlags, tagRequireVersion)
+	if requires == nil || len(requires.Entries) != 1 || requires.Entries[0].Name != "/bin/sh" {
+		t.Errorf("requires: got %+v", requires)
+	}
+	provides := dependencies(h, tagProvideName, tagProvideFlags, tagProvideVersion)
+	if want := (entryXML{Name: "otelcol", Flags: "EQ", Epoch: "0", Version: "0.150.0", Release: "1"}); provides == nil || provides.Entries[0] != want {
+		t.Errorf("provides: got %+v, want %+v", provides, want)
+	}
+}
+
+func TestRepositories(t *testing.T) {
+	dist := t.TempDir()
+	buildDeb(t, filepath.Join(dist, "otelcol_0.150.0_linux_amd64.deb"), testControl)
+	buildDeb(t, filepath.Join(dist, "otelcol_0.150.0_linux_arm64.deb"), strings.Replace(testControl, "amd64", "arm64", 1))
+	buildRPM(t, filepath.Join(dist, "otelcol_0.150.0_linux_amd64.rpm"), "linux")
+	buildRPM(t, filepath.Join(dist, "otelcol_0.150.0_aix_ppc64.rpm"), "aix")
+
+	out := t.TempDir()
+	aptDir, yumDir := filepath.Join(out, "apt"), filepath.Join(out, "yum")
+	debs, rpms, err := collect([]string{dist}, aptDir, yumDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if debs != 2 || rpms != 1 {
+		t.Fatalf("got %d debs and %d rpms, want 2 and 1", debs, rpms)
+	}
+
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	release, err := writeApt(aptDir, aptRepository{origin: "OpenTelemetry", suite: "stable", date: date})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Suite: stable\n",
+		"Date: Fri, 02 Jan 2026 03:04:05 UTC\n",
+		"Architectures: amd64 arm64\n",
+		" main/binary-arm64/Packages.gz\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Release doesn't contain %q:\n%s", want, data)
+		}
+	}
+	packages, err := os.ReadFile(filepath.Join(aptDir, "dists", "stable", "main", "binary-amd64", "Packages"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(packages), "Filename: pool/main/otelcol/otelcol_0.150.0_linux_amd64.deb\n") {
+		t.Errorf("Packages has no filename:\n%s", packages)
+	}
+	deb, err := os.ReadFile(filepath.Join(dist, "otelcol_0.150.0_linux_amd64.deb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		fmt.Sprintf("Size: %d\n", len(deb)),
+		fmt.Sprintf("SHA256: %x\n", sha256.Sum256(deb)),
+	} {
+		if !strings.Contains(string(packages), want) {
+			t.Errorf("Packages doesn't contain %q:\n%s", want, packages)
+		}
+	}
+
+	repomd, err := writeYum(yumDir, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(repomd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []string{"primary", "filelists", "other"} {
+		if !strings.Contains(string(data), `<data type="`+typ+`">`) {
+			t.Errorf("repomd.xml has no %s:\n%s", typ, data)
+		}
+	}
+	primary, err := filepath.Glob(filepath.Join(yumDir, "repodata", "*-primary.xml.gz"))
+	if err != nil || len(primary) != 1 {
+		t.Fatalf("primary: got %v, %v", primary, err)
+	}
+}
+
+// signedRepository builds and signs the repositories of a deb and an rpm with
+// a throwaway gpg key, and returns the output directory.
+func signedRepository(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not found")
+	}
+
+	home := t.TempDir()
+	const passphrase = "package-repo"
+	keyFile := filepath.Join(t.TempDir(), "signing-key.asc")
+	for _, args := range [][]string{
+		{"--quick-generate-key", "package-repo tests <package-repo@opentelemetry.io>", "ed25519", "sign", "1d"},
+		{"--armor", "--output=" + keyFile, "--export-secret-keys"},
+	} {
+		cmd := exec.Command("gpg", append([]string{"--batch", "--pinentry-mode=loopback", "--passphrase=" + passphrase}, args...)...)
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+home)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("gpg %s: %v\n%s", args[0], err, out)
+		}
+	}
+	t.Setenv(passphraseEnv, passphrase)
+	t.Setenv("SOURCE_DATE_EPOCH", "1767323045")
+
+	dist := t.TempDir()
+	buildDeb(t, filepath.Join(dist, "otelcol_0.150.0_linux_amd64.deb"), testControl)
+	buildRPM(t, filepath.Join(dist, "otelcol_0.150.0_linux_amd64.rpm"), "linux")
+
+	out := t.TempDir()
+	for flag, value := range map[*string]string{outFlag: out, keyFileFlag: keyFile} {
+		orig := *flag
+		*flag = value
+		t.Cleanup(func() { *flag = orig })
+	}
+	if err := run([]string{dist}); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// gpgVerify verifies a signature with the public key of the repository, in a
+// keyring of its own.
+func gpgVerify(t *testing.T, out string, args ...string) error {
+	t.Helper()
+	home := t.TempDir()
+	for _, args := range [][]string{{"--import", filepath.Join(out, "gpg.key")}, append([]string{"--verify"}, args...)} {
+		cmd := exec.Command("gpg", append([]string{"--batch"}, args...)...)
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+home)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("gpg %s: %w\n%s", args[0], err, out)
+		}
+	}
+	return nil
+}
+
+func TestSignedRepositories(t *testing.T) {
+	out := signedRepository(t)
+	dists := filepath.Join(out, "apt", "dists", "stable")
+	repomd := filepath.Join(out, "yum", "repodata", "repomd.xml")
+
+	for name, args := range map[string][]string{
+		"Release.gpg":    {filepath.Join(dists, "Release.gpg"), filepath.Join(dists, "Release")},
+		"InRelease":      {filepath.Join(dists, "InRelease")},
+		"repomd.xml.asc": {repomd + ".asc", repomd},
+	} {
+		if err := gpgVerify(t, out, args...); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	// The detached signatures must not verify modified metadata.
+	for path, signature := range map[string]string{
+		filepath.Join(dists, "Release"): filepath.Join(dists, "Release.gpg"),
+		repomd:                          repomd + ".asc",
+	} {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString("\n")
+		f.Close()
+		if err := gpgVerify(t, out, signature, path); err == nil {
+			t.Errorf("%s verifies modified metadata", filepath.Base(signature))
+		}
+	}
+}
+
+// TestAptRoundTrip reads the signed apt repository with apt itself, which
+// checks the InRelease signature and the Packages index checksums.
+func TestAptRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("apt-get"); err != nil {
+		t.Skip("apt-get not found")
+	}
+	out := signedRepository(t)
+
+	root := t.TempDir()
+	key := filepath.Join(root, "otelcol.asc")
+	if err := copyFile(filepath.Join(out, "gpg.key"), key); err != nil {
+		t.Fatal(err)
+	}
+	sources := filepath.Join(root, "sources.list")
+	source := fmt.Sprintf("deb [arch=amd64 signed-by=%s] file://%s stable main\n", key, filepath.Join(out, "apt"))
+	if err := os.WriteFile(sources, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"state/lists/partial", "cache/archives/partial"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status := filepath.Join(root, "status")
+	if err := os.WriteFile(status, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	apt := func(name string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command(name, append([]string{
+			"-o", "Dir::Etc::sourcelist=" + sources,
+			"-o", "Dir::Etc::sourceparts=-",
+			"-o", "Dir::Etc::trustedparts=-",
+			"-o", "Dir::State=" + filepath.Join(root, "state"),
+			"-o", "Dir::State::status=" + status,
+			"-o", "Dir::Cache=" + filepath.Join(root, "cache"),
+			"-o", "APT::Sandbox::User=" + currentUser(),
+			"-o", "Debug::NoLocking=1",
+		}, args...)...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s %s: %v\n%s", name, args[0], err, output)
+		}
+		return string(output)
+	}
+
+	if output := apt("apt-get", "update", "--error-on=any"); strings.Contains(output, "NO_PUBKEY") {
+		t.Fatalf("apt-get update doesn't trust the repository:\n%s", output)
+	}
+	show := apt("apt-cache", "show", "otelcol")
+	for _, want := range []string{"Version: 0.150.0\n", "Filename: pool/main/otelcol/otelcol_0.150.0_linux_amd64.deb\n", "SHA256: "} {
+		if !strings.Contains(show, want) {
+			t.Errorf("apt-cache show doesn't contain %q:\n%s", want, show)
+		}
+	}
+}
+
+// TestYumRoundTrip reads the yum repository with dnf, checking the repomd.xml
+// signature.
+func TestYumRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("dnf"); err != nil {
+		t.Skip("dnf not found")
+	}
+	out := signedRepository(t)
+
+	root := t.TempDir()
+	cmd := exec.Command("dnf", "repoquery",
+		"--quiet",
+		"--installroot="+root,
+		"--setopt=reposdir=/dev/null",
+		"--setopt=cachedir="+filepath.Join(root, "cache"),
+		"--repofrompath=otelcol,file://"+filepath.Join(out, "yum"),
+		"--repo=otelcol",
+		"--setopt=otelcol.gpgcheck=0",
+		"--setopt=otelcol.repo_gpgcheck=1",
+		"--setopt=otelcol.gpgkey=file://"+filepath.Join(out, "gpg.key"),
+		"--queryformat=%{name}-%{version}-%{release}.%{arch}\n",
+		"otelcol",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("dnf repoquery: %v\n%s", err, output)
+	}
+	if !strings.Contains(string(output), "otelcol-0.150.0-1.x86_64") {
+		t.Errorf("dnf repoquery doesn't list the package:\n%s", output)
+	}
+}
+
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return "root"
+	}
+	return u.Username
+}
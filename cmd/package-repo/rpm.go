@@ -0,0 +1,254 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	rpmLeadSize    = 96
+	rpmLeadMagic   = "\xed\xab\xee\xdb"
+	rpmHeaderMagic = "\x8e\xad\xe8\x01"
+)
+
+// RPM header tags used for the repository metadata.
+const (
+	tagName           = 1000
+	tagVersion        = 1001
+	tagRelease        = 1002
+	tagEpoch          = 1003
+	tagSummary        = 1004
+	tagDescription    = 1005
+	tagBuildTime      = 1006
+	tagBuildHost      = 1007
+	tagSize           = 1009
+	tagVendor         = 1011
+	tagLicense        = 1014
+	tagPackager       = 1015
+	tagGroup          = 1016
+	tagURL            = 1020
+	tagOS             = 1021
+	tagArch           = 1022
+	tagFileModes      = 1030
+	tagSourceRPM      = 1044
+	tagArchiveSize    = 1046
+	tagProvideName    = 1047
+	tagRequireFlags   = 1048
+	tagRequireName    = 1049
+	tagRequireVersion = 1050
+	tagConflictFlags  = 1053
+	tagConflictName   = 1054
+	tagConflictVer    = 1055
+	tagProvideFlags   = 1112
+	tagProvideVersion = 1113
+	tagDirIndexes     = 1116
+	tagBaseNames      = 1117
+	tagDirNames       = 1118
+)
+
+// RPM header data types.
+const (
+	typeInt16       = 3
+	typeInt32       = 4
+	typeString      = 6
+	typeStringArray = 8
+	typeI18NString  = 9
+)
+
+// rpmHeader is a parsed RPM header.
+type rpmHeader struct {
+	entries map[int32]rpmEntry
+	store   []byte
+}
+
+type rpmEntry struct {
+	typ, offset, count int32
+}
+
+// rpmPackage is the main header of an RPM package and its location in the file.
+type rpmPackage struct {
+	header rpmHeader
+	// headerStart and headerEnd are the byte range of the main header.
+	headerStart, headerEnd int64
+}
+
+// readRPM reads the main header of an RPM package.
+func readRPM(path string) (rpmPackage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return rpmPackage{}, err
+	}
+	defer f.Close()
+
+	pkg, err := parseRPM(f)
+	if err != nil {
+		return rpmPackage{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return pkg, nil
+}
+
+// parseRPM reads the main header of an RPM package, skipping the lead and the
+// signature header. It stops reading after the main header.
+func parseRPM(r io.Reader) (rpmPackage, error) {
+	lead := make([]byte, rpmLeadSize)
+	if _, err := io.ReadFull(r, lead); err != nil || string(lead[:4]) != rpmLeadMagic {
+		return rpmPackage{}, errors.New("not an rpm package")
+	}
+	sigSize, _, err := readRPMHeader(r)
+	if err != nil {
+		return rpmPackage{}, fmt.Errorf("signature header: %w", err)
+	}
+	// The signature header is padded to a multiple of 8 bytes.
+	if _, err := io.CopyN(io.Discard, r, (8-sigSize%8)%8); err != nil {
+		return rpmPackage{}, err
+	}
+	start := rpmLeadSize + sigSize + (8-sigSize%8)%8
+
+	size, header, err := readRPMHeader(r)
+	if err != nil {
+		return rpmPackage{}, fmt.Errorf("header: %w", err)
+	}
+	return rpmPackage{header: header, headerStart: start, headerEnd: start + size}, nil
+}
+
+// readRPMHeader reads a header structure and returns its size in bytes.
+func readRPMHeader(r io.Reader) (int64, rpmHeader, error) {
+	intro := make([]byte, 16)
+	if _, err := io.ReadFull(r, intro); err != nil {
+		return 0, rpmHeader{}, err
+	}
+	if string(intro[:4]) != rpmHeaderMagic {
+		return 0, rpmHeader{}, fmt.Errorf("invalid header magic")
+	}
+	count := binary.BigEndian.Uint32(intro[8:12])
+	storeSize := binary.BigEndian.Uint32(intro[12:16])
+	if count > 1<<16 || storeSize > 1<<28 {
+		return 0, rpmHeader{}, fmt.Errorf("header too large")
+	}
+
+	index := make([]byte, 16*count)
+	if _, err := io.ReadFull(r, index); err != nil {
+		return 0, rpmHeader{}, err
+	}
+	h := rpmHeader{entries: make(map[int32]rpmEntry, count), store: make([]byte, storeSize)}
+	if _, err := io.ReadFull(r, h.store); err != nil {
+		return 0, rpmHeader{}, err
+	}
+	for i := uint32(0); i < count; i++ {
+		e := index[16*i : 16*(i+1)]
+		h.entries[int32(binary.BigEndian.Uint32(e[0:4]))] = rpmEntry{
+			typ:    int32(binary.BigEndian.Uint32(e[4:8])),
+			offset: int32(binary.BigEndian.Uint32(e[8:12])),
+			count:  int32(binary.BigEndian.Uint32(e[12:16])),
+		}
+	}
+	return int64(16 + len(index) + len(h.store)), h, nil
+}
+
+// stringValues returns the values of a string, string array or i18n string tag.
+func (h rpmHeader) stringValues(tag int32) []string {
+	e, ok := h.entries[tag]
+	if !ok || e.offset < 0 || int(e.offset) > len(h.store) {
+		return nil
+	}
+	switch e.typ {
+	case typeString, typeStringArray, typeI18NString:
+	default:
+		return nil
+	}
+	n := e.count
+	if e.typ == typeString {
+		n = 1
+	}
+	values := make([]string, 0, n)
+	data := h.store[e.offset:]
+	for i := int32(0); i < n; i++ {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			break
+		}
+		values = append(values, string(data[:end]))
+		data = data[end+1:]
+	}
+	return values
+}
+
+// stringValue returns the first value of a string tag.
+func (h rpmHeader) stringValue(tag int32) string {
+	if values := h.stringValues(tag); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// intValues returns the values of an int16 or int32 tag.
+func (h rpmHeader) intValues(tag int32) []int64 {
+	e, ok := h.entries[tag]
+	if !ok || e.offset < 0 {
+		return nil
+	}
+	var size int32
+	switch e.typ {
+	case typeInt16:
+		size = 2
+	case typeInt32:
+		size = 4
+	default:
+		return nil
+	}
+	if e.count < 0 || int64(e.offset)+int64(size)*int64(e.count) > int64(len(h.store)) {
+		return nil
+	}
+	values := make([]int64, e.count)
+	for i := range values {
+		b := h.store[e.offset+size*int32(i):]
+		if size == 2 {
+			values[i] = int64(binary.BigEndian.Uint16(b))
+		} else {
+			values[i] = int64(binary.BigEndian.Uint32(b))
+		}
+	}
+	return values
+}
+
+// intValue returns the first value of an integer tag.
+func (h rpmHeader) intValue(tag int32) int64 {
+	if values := h.intValues(tag); len(values) > 0 {
+		return values[0]
+	}
+	return 0
+}
+
+// has reports whether the header holds a tag.
+func (h rpmHeader) has(tag int32) bool {
+	_, ok := h.entries[tag]
+	return ok
+}
+
+// files returns the paths of the packaged files and whether they are
+// directories.
+func (h rpmHeader) files() ([]string, []bool) {
+	baseNames := h.stringValues(tagBaseNames)
+	dirNames := h.stringValues(tagDirNames)
+	dirIndexes := h.intValues(tagDirIndexes)
+	modes := h.intValues(tagFileModes)
+
+	paths := make([]string, 0, len(baseNames))
+	dirs := make([]bool, 0, len(baseNames))
+	for i, base := range baseNames {
+		if i >= len(dirIndexes) || int(dirIndexes[i]) >= len(dirNames) {
+			break
+		}
+		paths = append(paths, dirNames[dirIndexes[i]]+base)
+		// S_IFDIR
+		dirs = append(dirs, i < len(modes) && modes[i]&0o170000 == 0o040000)
+	}
+	return paths, dirs
+}
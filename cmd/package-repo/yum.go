@@ -0,0 +1,422 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	xmlnsCommon    = "http://linux.duke.edu/metadata/common"
+	xmlnsRPM       = "http://linux.duke.edu/metadata/rpm"
+	xmlnsFilelists = "http://linux.duke.edu/metadata/filelists"
+	xmlnsOther     = "http://linux.duke.edu/metadata/other"
+	xmlnsRepo      = "http://linux.duke.edu/metadata/repo"
+)
+
+// RPM dependency flags.
+const (
+	senseLess    = 1 << 1
+	senseGreater = 1 << 2
+	senseEqual   = 1 << 3
+	senseRPMLib  = 1 << 24
+)
+
+// yumPackage is an rpm of the repository with its location and checksum.
+type yumPackage struct {
+	rpm      rpmPackage
+	location string
+	checksum string
+	size     int64
+	modTime  int64
+}
+
+// writeYum writes the repodata of the rpms in the Packages directory of dir:
+// the primary, filelists and other metadata and the repomd.xml index. It
+// returns the path of repomd.xml.
+func writeYum(dir string, date time.Time) (string, error) {
+	rpms, err := filepath.Glob(filepath.Join(dir, "Packages", "*.rpm"))
+	if err != nil {
+		return "", err
+	}
+	if len(rpms) == 0 {
+		return "", fmt.Errorf("no rpm packages in %s", dir)
+	}
+	sort.Strings(rpms)
+
+	var pkgs []yumPackage
+	for _, path := range rpms {
+		p, err := newYumPackage(dir, path)
+		if err != nil {
+			return "", err
+		}
+		pkgs = append(pkgs, p)
+	}
+
+	repodata := filepath.Join(dir, "repodata")
+	if err := os.RemoveAll(repodata); err != nil {
+		return "", err
+	}
+	repomd := repomdXML{Xmlns: xmlnsRepo, XmlnsRPM: xmlnsRPM, Revision: date.Unix()}
+	for _, md := range []struct {
+		name string
+		doc  any
+	}{
+		{"primary", newPrimary(pkgs)},
+		{"filelists", newFilelists(pkgs)},
+		{"other", newOther(pkgs)},
+	} {
+		data, err := marshalXML(md.doc)
+		if err != nil {
+			return "", err
+		}
+		gz, err := gzipBytes(data)
+		if err != nil {
+			return "", err
+		}
+		gzSum := hexSum(sha256.New(), gz)
+		// Checksum prefixed names keep caches of static hosts consistent.
+		location := fmt.Sprintf("repodata/%s-%s.xml.gz", gzSum, md.name)
+		if err := writeFile(filepath.Join(dir, location), gz); err != nil {
+			return "", err
+		}
+		repomd.Data = append(repomd.Data, repomdData{
+			Type:         md.name,
+			Checksum:     checksumXML{Type: "sha256", Value: gzSum},
+			OpenChecksum: checksumXML{Type: "sha256", Value: hexSum(sha256.New(), data)},
+			Location:     locationXML{Href: location},
+			Timestamp:    date.Unix(),
+			Size:         int64(len(gz)),
+			OpenSize:     int64(len(data)),
+		})
+	}
+
+	data, err := marshalXML(repomd)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(repodata, "repomd.xml")
+	return path, writeFile(path, data)
+}
+
+func newYumPackage(dir, path string) (yumPackage, error) {
+	var rpm rpmPackage
+	checksum := sha256.New()
+	size, err := hashPackage(path, func(r io.Reader) (err error) {
+		rpm, err = parseRPM(r)
+		return err
+	}, checksum)
+	if err != nil {
+		return yumPackage{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return yumPackage{}, err
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return yumPackage{}, err
+	}
+	return yumPackage{
+		rpm:      rpm,
+		location: filepath.ToSlash(rel),
+		checksum: hex.EncodeToString(checksum.Sum(nil)),
+		size:     size,
+		modTime:  info.ModTime().Unix(),
+	}, nil
+}
+
+func marshalXML(v any) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+type checksumXML struct {
+	Type  string `xml:"type,attr"`
+	PkgID string `xml:"pkgid,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type locationXML struct {
+	Href string `xml:"href,attr"`
+}
+
+type versionXML struct {
+	Epoch   string `xml:"epoch,attr"`
+	Version string `xml:"ver,attr"`
+	Release string `xml:"rel,attr"`
+}
+
+type repomdXML struct {
+	XMLName  xml.Name     `xml:"repomd"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	XmlnsRPM string       `xml:"xmlns:rpm,attr"`
+	Revision int64        `xml:"revision"`
+	Data     []repomdData `xml:"data"`
+}
+
+type repomdData struct {
+	Type         string      `xml:"type,attr"`
+	Checksum     checksumXML `xml:"checksum"`
+	OpenChecksum checksumXML `xml:"open-checksum"`
+	Location     locationXML `xml:"location"`
+	Timestamp    int64       `xml:"timestamp"`
+	Size         int64       `xml:"size"`
+	OpenSize     int64       `xml:"open-size"`
+}
+
+type primaryXML struct {
+	XMLName  xml.Name     `xml:"metadata"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	XmlnsRPM string       `xml:"xmlns:rpm,attr"`
+	Count    int          `xml:"packages,attr"`
+	Packages []primaryPkg `xml:"package"`
+}
+
+type primaryPkg struct {
+	Type        string      `xml:"type,attr"`
+	Name        string      `xml:"name"`
+	Arch        string      `xml:"arch"`
+	Version     versionXML  `xml:"version"`
+	Checksum    checksumXML `xml:"checksum"`
+	Summary     string      `xml:"summary"`
+	Description string      `xml:"description"`
+	Packager    string      `xml:"packager"`
+	URL         string      `xml:"url"`
+	Time        struct {
+		File  int64 `xml:"file,attr"`
+		Build int64 `xml:"build,attr"`
+	} `xml:"time"`
+	Size struct {
+		Package   int64 `xml:"package,attr"`
+		Installed int64 `xml:"installed,attr"`
+		Archive   int64 `xml:"archive,attr"`
+	} `xml:"size"`
+	Location locationXML   `xml:"location"`
+	Format   primaryFormat `xml:"format"`
+}
+
+type primaryFormat struct {
+	License     string `xml:"rpm:license"`
+	Vendor      string `xml:"rpm:vendor"`
+	Group       string `xml:"rpm:group"`
+	BuildHost   string `xml:"rpm:buildhost"`
+	SourceRPM   string `xml:"rpm:sourcerpm"`
+	HeaderRange struct {
+		Start int64 `xml:"start,attr"`
+		End   int64 `xml:"end,attr"`
+	} `xml:"rpm:header-range"`
+	Provides  *entriesXML `xml:"rpm:provides,omitempty"`
+	Requires  *entriesXML `xml:"rpm:requires,omitempty"`
+	Conflicts *entriesXML `xml:"rpm:conflicts,omitempty"`
+	Files     []fileXML   `xml:"file"`
+}
+
+type entriesXML struct {
+	Entries []entryXML `xml:"rpm:entry"`
+}
+
+type entryXML struct {
+	Name    string `xml:"name,attr"`
+	Flags   string `xml:"flags,attr,omitempty"`
+	Epoch   string `xml:"epoch,attr,omitempty"`
+	Version string `xml:"ver,attr,omitempty"`
+	Release string `xml:"rel,attr,omitempty"`
+}
+
+type fileXML struct {
+	Type string `xml:"type,attr,omitempty"`
+	Path string `xml:",chardata"`
+}
+
+type filelistsXML struct {
+	XMLName  xml.Name      `xml:"filelists"`
+	Xmlns    string        `xml:"xmlns,attr"`
+	Count    int           `xml:"packages,attr"`
+	Packages []filelistPkg `xml:"package"`
+}
+
+type filelistPkg struct {
+	PkgID   string     `xml:"pkgid,attr"`
+	Name    string     `xml:"name,attr"`
+	Arch    string     `xml:"arch,attr"`
+	Version versionXML `xml:"version"`
+	Files   []fileXML  `xml:"file"`
+}
+
+type otherXML struct {
+	XMLName  xml.Name   `xml:"otherdata"`
+	Xmlns    string     `xml:"xmlns,attr"`
+	Count    int        `xml:"packages,attr"`
+	Packages []otherPkg `xml:"package"`
+}
+
+type otherPkg struct {
+	PkgID   string     `xml:"pkgid,attr"`
+	Name    string     `xml:"name,attr"`
+	Arch    string     `xml:"arch,attr"`
+	Version versionXML `xml:"version"`
+}
+
+func (p yumPackage) version() versionXML {
+	h := p.rpm.header
+	return versionXML{
+		Epoch:   fmt.Sprint(h.intValue(tagEpoch)),
+		Version: h.stringValue(tagVersion),
+		Release: h.stringValue(tagRelease),
+	}
+}
+
+func newPrimary(pkgs []yumPackage) primaryXML {
+	doc := primaryXML{Xmlns: xmlnsCommon, XmlnsRPM: xmlnsRPM, Count: len(pkgs)}
+	for _, p := range pkgs {
+		h := p.rpm.header
+		pp := primaryPkg{
+			Type:        "rpm",
+			Name:        h.stringValue(tagName),
+			Arch:        h.stringValue(tagArch),
+			Version:     p.version(),
+			Checksum:    checksumXML{Type: "sha256", PkgID: "YES", Value: p.checksum},
+			Summary:     h.stringValue(tagSummary),
+			Description: h.stringValue(tagDescription),
+			Packager:    h.stringValue(tagPackager),
+			URL:         h.stringValue(tagURL),
+			Location:    locationXML{Href: p.location},
+		}
+		pp.Time.File = p.modTime
+		pp.Time.Build = h.intValue(tagBuildTime)
+		pp.Size.Package = p.size
+		pp.Size.Installed = h.intValue(tagSize)
+		pp.Size.Archive = h.intValue(tagArchiveSize)
+
+		f := &pp.Format
+		f.License = h.stringValue(tagLicense)
+		f.Vendor = h.stringValue(tagVendor)
+		f.Group = h.stringValue(tagGroup)
+		f.BuildHost = h.stringValue(tagBuildHost)
+		f.SourceRPM = h.stringValue(tagSourceRPM)
+		f.HeaderRange.Start = p.rpm.headerStart
+		f.HeaderRange.End = p.rpm.headerEnd
+		f.Provides = dependencies(h, tagProvideName, tagProvideFlags, tagProvideVersion)
+		f.Requires = dependencies(h, tagRequireName, tagRequireFlags, tagRequireVersion)
+		f.Conflicts = dependencies(h, tagConflictName, tagConflictFlags, tagConflictVer)
+		// Like createrepo, primary only lists the files commonly depended on.
+		for _, file := range files(h) {
+			if strings.HasPrefix(file.Path, "/etc/") || strings.Contains(file.Path, "bin/") {
+				f.Files = append(f.Files, file)
+			}
+		}
+		doc.Packages = append(doc.Packages, pp)
+	}
+	return doc
+}
+
+func newFilelists(pkgs []yumPackage) filelistsXML {
+	doc := filelistsXML{Xmlns: xmlnsFilelists, Count: len(pkgs)}
+	for _, p := range pkgs {
+		h := p.rpm.header
+		doc.Packages = append(doc.Packages, filelistPkg{
+			PkgID:   p.checksum,
+			Name:    h.stringValue(tagName),
+			Arch:    h.stringValue(tagArch),
+			Version: p.version(),
+			Files:   files(h),
+		})
+	}
+	return doc
+}
+
+func newOther(pkgs []yumPackage) otherXML {
+	doc := otherXML{Xmlns: xmlnsOther, Count: len(pkgs)}
+	for _, p := range pkgs {
+		h := p.rpm.header
+		doc.Packages = append(doc.Packages, otherPkg{
+			PkgID:   p.checksum,
+			Name:    h.stringValue(tagName),
+			Arch:    h.stringValue(tagArch),
+			Version: p.version(),
+		})
+	}
+	return doc
+}
+
+func files(h rpmHeader) []fileXML {
+	paths, dirs := h.files()
+	files := make([]fileXML, 0, len(paths))
+	for i, path := range paths {
+		file := fileXML{Path: path}
+		if dirs[i] {
+			file.Type = "dir"
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// dependencies returns the provides, requires or conflicts entries of a
+// header, without the rpmlib() requirements of rpm itself.
+func dependencies(h rpmHeader, nameTag, flagsTag, versionTag int32) *entriesXML {
+	names := h.stringValues(nameTag)
+	flags := h.intValues(flagsTag)
+	versions := h.stringValues(versionTag)
+
+	var entries []entryXML
+	for i, name := range names {
+		var flag int64
+		if i < len(flags) {
+			flag = flags[i]
+		}
+		if flag&senseRPMLib != 0 || strings.HasPrefix(name, "rpmlib(") {
+			continue
+		}
+		e := entryXML{Name: name}
+		if i < len(versions) && versions[i] != "" {
+			e.Flags = senseFlags(flag)
+			e.Epoch, e.Version, e.Release = splitEVR(versions[i])
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return &entriesXML{Entries: entries}
+}
+
+func senseFlags(flag int64) string {
+	switch flag & (senseLess | senseGreater | senseEqual) {
+	case senseEqual:
+		return "EQ"
+	case senseLess:
+		return "LT"
+	case senseGreater:
+		return "GT"
+	case senseLess | senseEqual:
+		return "LE"
+	case senseGreater | senseEqual:
+		return "GE"
+	}
+	return ""
+}
+
+// splitEVR splits [epoch:]version[-release].
+func splitEVR(evr string) (epoch, version, release string) {
+	epoch = "0"
+	if e, rest, ok := strings.Cut(evr, ":"); ok {
+		epoch, evr = e, rest
+	}
+	version, release, _ = strings.Cut(evr, "-")
+	return epoch, version, release
+}
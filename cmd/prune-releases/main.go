@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// prune-releases deletes the GitHub releases of a release channel beyond its
+// retention, together with their tags. It is run by goreleaser after the
+// releases of channels keeping several of them, see UseReleaseChannel in
+// cmd/goreleaser. Releases are listed and deleted with the gh CLI, which reads
+// the token from GITHUB_TOKEN.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	tagPrefixFlag = flag.String("tag-prefix", "", "Tag prefix of the channel releases, e.g. rc-otelcol-")
+	keepFlag      = flag.Int("keep", 3, "Number of the most recent releases kept")
+	repoFlag      = flag.String("repo", "", "GitHub repository, e.g. open-telemetry/opentelemetry-collector-releases, the current one if empty")
+	dryRunFlag    = flag.Bool("dry-run", false, "Print the releases that would be deleted without deleting them")
+)
+
+// release is a GitHub release as listed by gh release list.
+type release struct {
+	TagName   string    `json:"tagName"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func main() {
+	flag.Parse()
+
+	if *tagPrefixFlag == "" {
+		log.Fatal("-tag-prefix is required")
+	}
+	if *keepFlag < 1 {
+		log.Fatal("-keep must be at least 1")
+	}
+	if err := prune(*tagPrefixFlag, *keepFlag); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func prune(prefix string, keep int) error {
+	out, err := gh("release", "list", "--limit=1000", "--json=tagName,createdAt")
+	if err != nil {
+		return err
+	}
+	var releases []release
+	if err := json.Unmarshal(out, &releases); err != nil {
+		return fmt.Errorf("parsing releases: %w", err)
+	}
+	for _, r := range expired(releases, prefix, keep) {
+		if *dryRunFlag {
+			log.Printf("would delete %s", r.TagName)
+			continue
+		}
+		log.Printf("deleting %s", r.TagName)
+		if _, err := gh("release", "delete", r.TagName, "--yes", "--cleanup-tag"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expired returns the releases tagged with the prefix and a version, except
+// the keep most recent ones. Requiring the version keeps rc-otelcol- from
+// matching the rc-otelcol-contrib- releases.
+func expired(releases []release, prefix string, keep int) []release {
+	var matching []release
+	for _, r := range releases {
+		version, ok := strings.CutPrefix(r.TagName, prefix)
+		if ok && version != "" && version[0] >= '0' && version[0] <= '9' {
+			matching = append(matching, r)
+		}
+	}
+	if len(matching) <= keep {
+		return nil
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].CreatedAt.After(matching[j].CreatedAt)
+	})
+	return matching[keep:]
+}
+
+func gh(args ...string) ([]byte, error) {
+	if *repoFlag != "" {
+		args = append(args, "--repo="+*repoFlag)
+	}
+	cmd := exec.Command("gh", args...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gh %s %s: %w", args[0], args[1], err)
+	}
+	return out, nil
+}
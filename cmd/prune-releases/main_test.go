@@ -0,0 +1,35 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExpired(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	releases := []release{
+		{TagName: "rc-otelcol-0.161.0-rc.aaaaaaa", CreatedAt: day(1)},
+		{TagName: "rc-otelcol-0.161.0-rc.ccccccc", CreatedAt: day(3)},
+		{TagName: "v0.160.0", CreatedAt: day(2)},
+		{TagName: "rc-otelcol-contrib-0.161.0-rc.aaaaaaa", CreatedAt: day(1)},
+		{TagName: "rc-otelcol-0.161.0-rc.bbbbbbb", CreatedAt: day(2)},
+		{TagName: "rc-otelcol-0.161.0-rc.ddddddd", CreatedAt: day(4)},
+	}
+
+	var got []string
+	for _, r := range expired(releases, "rc-otelcol-", 2) {
+		got = append(got, r.TagName)
+	}
+	want := []string{"rc-otelcol-0.161.0-rc.bbbbbbb", "rc-otelcol-0.161.0-rc.aaaaaaa"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := expired(releases, "rc-otelcol-", 4); got != nil {
+		t.Errorf("got %v, want none expired", got)
+	}
+}
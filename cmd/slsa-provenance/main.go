@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// slsa-provenance generates SLSA v1 provenance for a release artifact or a
+// container image and attaches it with cosign. It is run by goreleaser for
+// every archive, package and image, see withProvenance in cmd/goreleaser.
+// The attestations are signed as described in internal/cosign.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-releases/internal/cosign"
+)
+
+const (
+	predicateType = "slsaprovenance1"
+	buildType     = "https://github.com/open-telemetry/opentelemetry-collector-releases/goreleaser@v1"
+)
+
+var (
+	artifactFlag     = flag.String("artifact", "", "Path of the archive or package to attest")
+	bundleFlag       = flag.String("bundle", "", "Path to write the attestation bundle of -artifact to")
+	imageFlag        = flag.String("image", "", "Container image reference, with digest, to attest")
+	distributionFlag = flag.String("distribution", "", "Distribution or binary the artifact belongs to")
+	versionFlag      = flag.String("version", "", "Released version")
+	commitFlag       = flag.String("commit", "", "Full git commit the release was built from")
+	sourceFlag       = flag.String("source", "", "Git URL of the source repository")
+	predicateFlag    = flag.Bool("predicate-only", false, "Print the predicate instead of attesting")
+)
+
+// release describes the build producing the attested artifacts.
+type release struct {
+	Distribution string
+	Version      string
+	Commit       string
+	Source       string
+}
+
+func main() {
+	flag.Parse()
+
+	r := release{
+		Distribution: *distributionFlag,
+		Version:      *versionFlag,
+		Commit:       *commitFlag,
+		Source:       *sourceFlag,
+	}
+	predicate, err := json.MarshalIndent(newPredicate(r, time.Now().UTC()), "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *predicateFlag {
+		fmt.Println(string(predicate))
+		return
+	}
+
+	switch {
+	case *artifactFlag != "" && *bundleFlag != "":
+		err = attestBlob(predicate, *artifactFlag, *bundleFlag)
+	case *imageFlag != "":
+		err = attestImage(predicate, *imageFlag)
+	default:
+		log.Fatal("either -artifact and -bundle or -image is required")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newPredicate returns the SLSA v1 provenance predicate of a release, see
+// https://slsa.dev/spec/v1.0/provenance. GitHub Actions runs fill in the
+// builder and invocation.
+func newPredicate(r release, startedOn time.Time) map[string]any {
+	builderID := "local"
+	invocationID := ""
+	if server, repo := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"); server != "" && repo != "" {
+		builderID = fmt.Sprintf("%s/%s", server, os.Getenv("GITHUB_WORKFLOW_REF"))
+		invocationID = fmt.Sprintf("%s/%s/actions/runs/%s/attempts/%s",
+			server, repo, os.Getenv("GITHUB_RUN_ID"), os.Getenv("GITHUB_RUN_ATTEMPT"))
+	}
+
+	return map[string]any{
+		"buildDefinition": map[string]any{
+			"buildType": buildType,
+			"externalParameters": map[string]any{
+				"distribution": r.Distribution,
+				"version":      r.Version,
+			},
+			"internalParameters": map[string]any{
+				"github": map[string]any{
+					"event_name": os.Getenv("GITHUB_EVENT_NAME"),
+					"ref":        os.Getenv("GITHUB_REF"),
+				},
+			},
+			"resolvedDependencies": []any{
+				map[string]any{
+					"uri":    "git+" + r.Source,
+					"digest": map[string]string{"gitCommit": r.Commit},
+				},
+			},
+		},
+		"runDetails": map[string]any{
+			"builder": map[string]any{"id": builderID},
+			"metadata": map[string]any{
+				"invocationId": invocationID,
+				"startedOn":    startedOn.Format(time.RFC3339),
+			},
+		},
+	}
+}
+
+// attestBlob attaches the provenance of a file as a sigstore bundle.
+func attestBlob(predicate []byte, artifact, bundle string) error {
+	path, cleanup, err := writePredicate(predicate)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	return runCosign(append([]string{
+		"attest-blob",
+		"--yes",
+		"--predicate=" + path,
+		"--type=" + predicateType,
+		"--bundle=" + bundle,
+	}, append(cosign.KeyArgs(), artifact)...)...)
+}
+
+// attestImage pushes the provenance of an image to its registry.
+func attestImage(predicate []byte, image string) error {
+	path, cleanup, err := writePredicate(predicate)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	return runCosign(append([]string{
+		"attest",
+		"--yes",
+		"--predicate=" + path,
+		"--type=" + predicateType,
+	}, append(cosign.KeyArgs(), image)...)...)
+}
+
+func writePredicate(predicate []byte) (string, func(), error) {
+	f, err := os.CreateTemp("", "provenance-*.json")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(f.Name()) }
+	if _, err := f.Write(predicate); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return f.Name(), cleanup, nil
+}
+
+func runCosign(args ...string) error {
+	cmd := exec.Command("cosign", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cosign %s: %w", args[0], err)
+	}
+	return nil
+}
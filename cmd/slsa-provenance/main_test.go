@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testRelease = release{
+	Distribution: "otelcol",
+	Version:      "0.150.0",
+	Commit:       "0123456789abcdef0123456789abcdef01234567",
+	Source:       "https://github.com/open-telemetry/opentelemetry-collector-releases.git",
+}
+
+func TestNewPredicate(t *testing.T) {
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "open-telemetry/opentelemetry-collector-releases")
+	t.Setenv("GITHUB_WORKFLOW_REF", "open-telemetry/opentelemetry-collector-releases/.github/workflows/release-core.yaml@refs/tags/v0.150.0")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	t.Setenv("GITHUB_RUN_ATTEMPT", "1")
+
+	data, err := json.Marshal(newPredicate(testRelease, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var predicate struct {
+		BuildDefinition struct {
+			BuildType          string            `json:"buildType"`
+			ExternalParameters map[string]string `json:"externalParameters"`
+			ResolvedDeps       []struct {
+				URI    string            `json:"uri"`
+				Digest map[string]string `json:"digest"`
+			} `json:"resolvedDependencies"`
+		} `json:"buildDefinition"`
+		RunDetails struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			Metadata struct {
+				InvocationID string `json:"invocationId"`
+				StartedOn    string `json:"startedOn"`
+			} `json:"metadata"`
+		} `json:"runDetails"`
+	}
+	if err := json.Unmarshal(data, &predicate); err != nil {
+		t.Fatal(err)
+	}
+
+	def := predicate.BuildDefinition
+	if def.BuildType != buildType {
+		t.Errorf("buildType = %q, want %q", def.BuildType, buildType)
+	}
+	if got := def.ExternalParameters["distribution"]; got != "otelcol" {
+		t.Errorf("distribution = %q, want otelcol", got)
+	}
+	if got := def.ExternalParameters["version"]; got != "0.150.0" {
+		t.Errorf("version = %q, want 0.150.0", got)
+	}
+	if len(def.ResolvedDeps) != 1 || def.ResolvedDeps[0].Digest["gitCommit"] != testRelease.Commit {
+		t.Errorf("resolvedDependencies = %+v, want the release commit", def.ResolvedDeps)
+	}
+	if want := "git+" + testRelease.Source; len(def.ResolvedDeps) == 1 && def.ResolvedDeps[0].URI != want {
+		t.Errorf("uri = %q, want %q", def.ResolvedDeps[0].URI, want)
+	}
+
+	run := predicate.RunDetails
+	if want := "https://github.com/open-telemetry/opentelemetry-collector-releases/.github/workflows/release-core.yaml@refs/tags/v0.150.0"; run.Builder.ID != want {
+		t.Errorf("builder id = %q, want %q", run.Builder.ID, want)
+	}
+	if want := "https://github.com/open-telemetry/opentelemetry-collector-releases/actions/runs/42/attempts/1"; run.Metadata.InvocationID != want {
+		t.Errorf("invocationId = %q, want %q", run.Metadata.InvocationID, want)
+	}
+	if want := "2026-01-02T03:04:05Z"; run.Metadata.StartedOn != want {
+		t.Errorf("startedOn = %q, want %q", run.Metadata.StartedOn, want)
+	}
+}
+
+func TestNewPredicateLocal(t *testing.T) {
+	t.Setenv("GITHUB_SERVER_URL", "")
+	t.Setenv("GITHUB_REPOSITORY", "")
+
+	runDetails := newPredicate(testRelease, time.Now())["runDetails"].(map[string]any)
+	if got := runDetails["builder"].(map[string]any)["id"]; got != "local" {
+		t.Errorf("builder id = %v, want local", got)
+	}
+}
+
+// TestAttestBlobOffline attests a file with a local key and verifies the
+// attestation without the transparency log.
+func TestAttestBlobOffline(t *testing.T) {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		t.Skip("cosign not found")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("COSIGN_PASSWORD", "")
+	keygen := exec.Command("cosign", "generate-key-pair")
+	keygen.Dir = dir
+	if out, err := keygen.CombinedOutput(); err != nil {
+		t.Fatalf("generate-key-pair: %v\n%s", err, out)
+	}
+	t.Setenv("COSIGN_KEY", filepath.Join(dir, "cosign.key"))
+
+	artifact := filepath.Join(dir, "otelcol_0.150.0_linux_amd64.tar.gz")
+	if err := os.WriteFile(artifact, []byte("archive"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	predicate, err := json.Marshal(newPredicate(testRelease, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := artifact + ".intoto.bundle"
+	if err := attestBlob(predicate, artifact, bundle); err != nil {
+		t.Fatal(err)
+	}
+
+	verify := exec.Command("cosign", "verify-blob-attestation",
+		"--key="+filepath.Join(dir, "cosign.pub"),
+		"--insecure-ignore-tlog",
+		"--type="+predicateType,
+		"--bundle="+bundle,
+		artifact,
+	)
+	if out, err := verify.CombinedOutput(); err != nil {
+		t.Fatalf("verify-blob-attestation: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,436 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// verify-release checks a directory of downloaded release artifacts without
+// network access. For every artifact it validates the split checksum, verifies
+// the sigstore bundles of the signature and the provenance against a trusted
+// root, or the gpg signature against a keyring, and checks that the SBOM describes the artifact by its digest. It
+// prints a report per artifact and exits non-zero on any failure. The release
+// files published next to the artifacts, the component SBOM and the
+// inventory, have no checksum nor signature and are reported unchecked.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	checksumSuffix = ".sha256"
+	sbomSuffix     = ".sbom.json"
+	// keySignatureSuffix and keyProvenanceSuffix name the bundles of releases
+	// signed with a key, see the -signing modes of cmd/goreleaser.
+	keySignatureSuffix  = ".bundle"
+	keyProvenanceSuffix = ".intoto.bundle"
+	// gpgSignatureSuffix names the detached signatures of releases signed with
+	// gpg, which have no provenance.
+	gpgSignatureSuffix = ".asc"
+)
+
+// signatureSuffix and provenanceSuffix name the bundles of keyless releases,
+// or of releases signed with a key when -key is set.
+var (
+	signatureSuffix  = ".sigstore.json"
+	provenanceSuffix = ".intoto.sigstore.json"
+)
+
+// signatureSuffixes name the signatures of every signing mode, which are never
+// artifacts, even when checking a release in another mode than it was signed.
+var signatureSuffixes = []string{".sigstore.json", keySignatureSuffix, gpgSignatureSuffix}
+
+// releaseFileSuffixes name the files goreleaser publishes as release extra
+// files, which it neither checksums nor signs.
+var releaseFileSuffixes = []string{"_components.cdx.json", "_inventory.json"}
+
+// errFailed reports that at least one artifact failed verification.
+var errFailed = errors.New("verification failed")
+
+var (
+	dirFlag          = flag.String("dir", ".", "Directory holding the downloaded artifacts")
+	trustedRootFlag  = flag.String("trusted-root", "", "Sigstore trusted root file to verify bundles against")
+	keyFlag          = flag.String("key", "", "Public key to verify bundles with, instead of certificates")
+	gpgKeyringFlag   = flag.String("gpg-keyring", "", "GPG public keyring to verify the .asc signatures of gpg signed releases with")
+	identityFlag     = flag.String("certificate-identity-regexp", `^https://github\.com/open-telemetry/opentelemetry-collector-releases/\.github/workflows/`, "Expected signer identity of keyless bundles")
+	issuerFlag       = flag.String("certificate-oidc-issuer", "https://token.actions.githubusercontent.com", "Expected OIDC issuer of keyless bundles")
+	allowMissingFlag = flag.Bool("allow-missing", false, "Don't fail on artifacts without checksum or signature")
+)
+
+// status is the outcome of one check of an artifact.
+type status string
+
+const (
+	statusOK      status = "ok"
+	statusFailed  status = "FAILED"
+	statusMissing status = "missing"
+	statusNone    status = "-"
+)
+
+// result holds the checks of one artifact.
+type result struct {
+	artifact   string
+	checksum   status
+	signature  status
+	provenance status
+	sbom       status
+	errors     []string
+}
+
+func (r *result) failed() bool {
+	for _, s := range []status{r.checksum, r.signature, r.provenance, r.sbom} {
+		if s == statusFailed || (s == statusMissing && !*allowMissingFlag) {
+			return true
+		}
+	}
+	return false
+}
+
+func main() {
+	flag.Parse()
+
+	switch {
+	case *gpgKeyringFlag != "":
+		// gpg looks relative keyrings up in its home directory.
+		keyring, err := filepath.Abs(*gpgKeyringFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		*gpgKeyringFlag = keyring
+		signatureSuffix = gpgSignatureSuffix
+	case *keyFlag != "":
+		signatureSuffix, provenanceSuffix = keySignatureSuffix, keyProvenanceSuffix
+	case *trustedRootFlag == "":
+		log.Fatal("-trusted-root, -key or -gpg-keyring is required")
+	}
+	if err := run(*dirFlag, os.Stdout, os.Stderr); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run verifies the artifacts of dir, writing the report to stdout and the
+// errors to stderr.
+func run(dir string, stdout, stderr io.Writer) error {
+	artifacts, err := listArtifacts(dir)
+	if err != nil {
+		return err
+	}
+	if len(artifacts) == 0 {
+		return fmt.Errorf("no artifacts found in %s", dir)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ARTIFACT\tCHECKSUM\tSIGNATURE\tPROVENANCE\tSBOM")
+	var failed bool
+	var messages []string
+	for _, artifact := range artifacts {
+		r := verify(dir, artifact)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.artifact, r.checksum, r.signature, r.provenance, r.sbom)
+		if r.failed() {
+			failed = true
+		}
+		messages = append(messages, r.errors...)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, e := range messages {
+		fmt.Fprintln(stderr, e)
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+// listArtifacts returns the files of dir that are neither checksums nor
+// signatures. SBOMs are artifacts themselves, with checksums and signatures.
+func listArtifacts(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, checksumSuffix) || hasSuffix(name, signatureSuffixes) {
+			continue
+		}
+		artifacts = append(artifacts, name)
+	}
+	sort.Strings(artifacts)
+	return artifacts, nil
+}
+
+// isReleaseFile reports whether a file is published as a release extra file.
+func isReleaseFile(name string) bool {
+	return hasSuffix(name, releaseFileSuffixes)
+}
+
+func hasSuffix(name string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func verify(dir, artifact string) result {
+	r := result{artifact: artifact, provenance: statusNone, sbom: statusNone}
+	if isReleaseFile(artifact) {
+		r.checksum, r.signature = statusNone, statusNone
+		return r
+	}
+	path := filepath.Join(dir, artifact)
+	fail := func(check string, err error) status {
+		r.errors = append(r.errors, fmt.Sprintf("%s: %s: %v", artifact, check, err))
+		return statusFailed
+	}
+
+	switch err := verifyChecksum(path); {
+	case os.IsNotExist(err):
+		r.checksum = statusMissing
+	case err != nil:
+		r.checksum = fail("checksum", err)
+	default:
+		r.checksum = statusOK
+	}
+
+	if _, err := os.Stat(path + signatureSuffix); os.IsNotExist(err) {
+		r.signature = statusMissing
+	} else if err := verifySignature(path, path+signatureSuffix); err != nil {
+		r.signature = fail("signature", err)
+	} else {
+		r.signature = statusOK
+	}
+
+	if _, err := os.Stat(path + provenanceSuffix); err == nil {
+		if err := verifyAttestation(path, path+provenanceSuffix, "slsaprovenance1"); err != nil {
+			r.provenance = fail("provenance", err)
+		} else {
+			r.provenance = statusOK
+		}
+	}
+
+	if _, err := os.Stat(path + sbomSuffix); err == nil {
+		if err := verifySBOM(path, path+sbomSuffix); err != nil {
+			r.sbom = fail("sbom", err)
+		} else {
+			r.sbom = statusOK
+		}
+	}
+	return r
+}
+
+// verifyChecksum compares the SHA-256 of an artifact with its split checksum
+// file, holding the hex digest optionally followed by the file name.
+func verifyChecksum(path string) error {
+	data, err := os.ReadFile(path + checksumSuffix)
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return fmt.Errorf("empty checksum file")
+	}
+
+	got, err := sha256File(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(got, fields[0]) {
+		return fmt.Errorf("sha256 is %s, want %s", got, fields[0])
+	}
+	return nil
+}
+
+// sha256File returns the hex SHA-256 of a file.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// spdxChecksum is a checksum of an SPDX package or file.
+type spdxChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+// sbomDocument holds the parts of an SPDX or CycloneDX document describing
+// its subject.
+type sbomDocument struct {
+	// SPDX
+	SPDXVersion       string   `json:"spdxVersion"`
+	DocumentDescribes []string `json:"documentDescribes"`
+	Packages          []struct {
+		SPDXID    string         `json:"SPDXID"`
+		Checksums []spdxChecksum `json:"checksums"`
+	} `json:"packages"`
+	Files []struct {
+		SPDXID    string         `json:"SPDXID"`
+		Checksums []spdxChecksum `json:"checksums"`
+	} `json:"files"`
+	Relationships []struct {
+		Element string `json:"spdxElementId"`
+		Related string `json:"relatedSpdxElement"`
+		Type    string `json:"relationshipType"`
+	} `json:"relationships"`
+	// CycloneDX
+	BOMFormat string `json:"bomFormat"`
+	Metadata  struct {
+		Component struct {
+			Version string `json:"version"`
+			Hashes  []struct {
+				Alg     string `json:"alg"`
+				Content string `json:"content"`
+			} `json:"hashes"`
+		} `json:"component"`
+	} `json:"metadata"`
+}
+
+// subjectDigests returns the SHA-256 digests of the subject of the document:
+// the packages and files an SPDX document describes, or the CycloneDX
+// metadata component.
+func (d *sbomDocument) subjectDigests() []string {
+	var digests []string
+	if d.BOMFormat == "CycloneDX" {
+		for _, hash := range d.Metadata.Component.Hashes {
+			if hash.Alg == "SHA-256" {
+				digests = append(digests, hash.Content)
+			}
+		}
+		if digest, ok := strings.CutPrefix(d.Metadata.Component.Version, "sha256:"); ok {
+			digests = append(digests, digest)
+		}
+		return digests
+	}
+
+	described := map[string]bool{}
+	for _, id := range d.DocumentDescribes {
+		described[id] = true
+	}
+	for _, r := range d.Relationships {
+		if r.Element == "SPDXRef-DOCUMENT" && r.Type == "DESCRIBES" {
+			described[r.Related] = true
+		}
+	}
+	add := func(id string, checksums []spdxChecksum) {
+		if !described[id] {
+			return
+		}
+		for _, c := range checksums {
+			if c.Algorithm == "SHA256" {
+				digests = append(digests, c.Value)
+			}
+		}
+	}
+	for _, p := range d.Packages {
+		add(p.SPDXID, p.Checksums)
+	}
+	for _, f := range d.Files {
+		add(f.SPDXID, f.Checksums)
+	}
+	return digests
+}
+
+// verifySBOM checks that an SPDX or CycloneDX document describes the artifact
+// at path, by its SHA-256 digest.
+func verifySBOM(path, sbomPath string) error {
+	data, err := os.ReadFile(sbomPath)
+	if err != nil {
+		return err
+	}
+	var doc sbomDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parsing %s: %w", filepath.Base(sbomPath), err)
+	}
+	if doc.SPDXVersion == "" && doc.BOMFormat != "CycloneDX" {
+		return fmt.Errorf("%s is neither SPDX nor CycloneDX", filepath.Base(sbomPath))
+	}
+
+	digests := doc.subjectDigests()
+	if len(digests) == 0 {
+		return fmt.Errorf("%s has no SHA-256 digest of its subject", filepath.Base(sbomPath))
+	}
+	want, err := sha256File(path)
+	if err != nil {
+		return err
+	}
+	for _, digest := range digests {
+		if strings.EqualFold(digest, want) {
+			return nil
+		}
+	}
+	return fmt.Errorf("describes sha256 %s, not the artifact's %s", strings.Join(digests, ", "), want)
+}
+
+// verifySignature verifies the signature of an artifact, against the keyring
+// with -gpg-keyring and as a sigstore bundle otherwise.
+func verifySignature(artifact, signature string) error {
+	if *gpgKeyringFlag != "" {
+		return gpg("--batch", "--no-default-keyring", "--keyring="+*gpgKeyringFlag, "--verify", signature, artifact)
+	}
+	return verifyBundle(artifact, signature)
+}
+
+// verifyBundle verifies a sigstore bundle of a blob offline.
+func verifyBundle(artifact, bundle string) error {
+	return cosign(append([]string{"verify-blob", "--bundle=" + bundle}, append(trustArgs(), artifact)...)...)
+}
+
+// verifyAttestation verifies an attestation bundle of a blob offline.
+func verifyAttestation(artifact, bundle, predicateType string) error {
+	return cosign(append([]string{"verify-blob-attestation", "--bundle=" + bundle, "--type=" + predicateType}, append(trustArgs(), artifact)...)...)
+}
+
+// trustArgs returns the cosign arguments to verify against the public key or
+// the trusted root and the expected identity, without network access.
+func trustArgs() []string {
+	if *keyFlag != "" {
+		return []string{"--key=" + *keyFlag, "--insecure-ignore-tlog", "--offline"}
+	}
+	return []string{
+		"--trusted-root=" + *trustedRootFlag,
+		"--certificate-identity-regexp=" + *identityFlag,
+		"--certificate-oidc-issuer=" + *issuerFlag,
+		"--offline",
+	}
+}
+
+// cosign runs cosign, replaced in tests.
+var cosign = func(args ...string) error {
+	out, err := exec.Command("cosign", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cosign %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// gpg runs gpg, replaced in tests.
+var gpg = func(args ...string) error {
+	out, err := exec.Command("gpg", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("gpg --verify: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,473 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testArtifact = "otelcol_0.150.0_linux_amd64.tar.gz"
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, testArtifact, "archive")
+
+	if err := verifyChecksum(path); !os.IsNotExist(err) {
+		t.Errorf("missing checksum: got %v, want not exist", err)
+	}
+
+	h := sha256.Sum256([]byte("archive"))
+	sum := hex.EncodeToString(h[:])
+	writeFile(t, dir, testArtifact+checksumSuffix, sum+"  "+testArtifact+"\n")
+	if err := verifyChecksum(path); err != nil {
+		t.Errorf("matching checksum: %v", err)
+	}
+
+	writeFile(t, dir, testArtifact+checksumSuffix, sum)
+	if err := verifyChecksum(path); err != nil {
+		t.Errorf("matching checksum without name: %v", err)
+	}
+
+	writeFile(t, dir, testArtifact+checksumSuffix, strings.Repeat("0", sha256.Size*2))
+	if err := verifyChecksum(path); err == nil {
+		t.Error("mismatching checksum: got no error")
+	}
+}
+
+func TestVerifySBOM(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, testArtifact, "archive")
+	h := sha256.Sum256([]byte("archive"))
+	sum := hex.EncodeToString(h[:])
+	other := strings.Repeat("0", sha256.Size*2)
+
+	spdx := func(describes, digest string) string {
+		return `{"spdxVersion": "SPDX-2.3", "name": "` + testArtifact + `",
+			"packages": [{"SPDXID": "SPDXRef-DocumentRoot-File-archive", "checksums": [{"algorithm": "SHA256", "checksumValue": "` + digest + `"}]}],
+			"relationships": [{"spdxElementId": "SPDXRef-DOCUMENT", "relatedSpdxElement": "` + describes + `", "relationshipType": "DESCRIBES"}]}`
+	}
+	for _, tt := range []struct {
+		name    string
+		doc     string
+		wantErr bool
+	}{
+		{"spdx", spdx("SPDXRef-DocumentRoot-File-archive", sum), false},
+		{"spdx uppercase digest", spdx("SPDXRef-DocumentRoot-File-archive", strings.ToUpper(sum)), false},
+		{"spdx documentDescribes", `{"spdxVersion": "SPDX-2.3", "documentDescribes": ["SPDXRef-File-archive"],
+			"files": [{"SPDXID": "SPDXRef-File-archive", "checksums": [{"algorithm": "SHA256", "checksumValue": "` + sum + `"}]}]}`, false},
+		{"spdx other digest", spdx("SPDXRef-DocumentRoot-File-archive", other), true},
+		{"spdx package not described", spdx("SPDXRef-Package-other", sum), true},
+		{"spdx same name without digest", `{"spdxVersion": "SPDX-2.3", "name": "` + testArtifact + `"}`, true},
+		{"cyclonedx hash", `{"bomFormat": "CycloneDX", "metadata": {"component": {"name": "` + testArtifact + `",
+			"hashes": [{"alg": "SHA-256", "content": "` + sum + `"}]}}}`, false},
+		{"cyclonedx version", `{"bomFormat": "CycloneDX", "metadata": {"component": {"version": "sha256:` + sum + `"}}}`, false},
+		{"cyclonedx other digest", `{"bomFormat": "CycloneDX", "metadata": {"component": {"version": "sha256:` + other + `"}}}`, true},
+		{"unknown format", `{"name": "` + testArtifact + `"}`, true},
+		{"invalid", `{`, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sbom := writeFile(t, dir, testArtifact+sbomSuffix, tt.doc)
+			if err := verifySBOM(path, sbom); (err != nil) != tt.wantErr {
+				t.Errorf("got %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeCosign accepts the bundles holding "valid" and records the calls.
+func fakeCosign(t *testing.T) *[]string {
+	t.Helper()
+	var calls []string
+	orig := cosign
+	t.Cleanup(func() { cosign = orig })
+	cosign = func(args ...string) error {
+		calls = append(calls, args[0])
+		bundle, _ := strings.CutPrefix(args[1], "--bundle=")
+		data, err := os.ReadFile(bundle)
+		if err != nil {
+			return err
+		}
+		if string(data) != "valid" {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return &calls
+}
+
+// writeRelease writes a verifiable artifact with its checksum, signature,
+// provenance and SBOM, and the unsigned release files.
+func writeRelease(t *testing.T, dir string) {
+	t.Helper()
+	h := sha256.Sum256([]byte("archive"))
+	sum := hex.EncodeToString(h[:])
+	writeFile(t, dir, testArtifact, "archive")
+	writeFile(t, dir, testArtifact+checksumSuffix, sum+"  "+testArtifact+"\n")
+	writeFile(t, dir, testArtifact+signatureSuffix, "valid")
+	writeFile(t, dir, testArtifact+provenanceSuffix, "valid")
+	sbom := `{"bomFormat": "CycloneDX", "metadata": {"component": {"version": "sha256:` + sum + `"}}}`
+	writeFile(t, dir, testArtifact+sbomSuffix, sbom)
+	h = sha256.Sum256([]byte(sbom))
+	writeFile(t, dir, testArtifact+sbomSuffix+checksumSuffix, hex.EncodeToString(h[:]))
+	writeFile(t, dir, testArtifact+sbomSuffix+signatureSuffix, "valid")
+	writeFile(t, dir, "otelcol_0.150.0_components.cdx.json", "{}")
+	writeFile(t, dir, "otelcol_0.150.0_inventory.json", "{}")
+}
+
+func TestVerify(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		modify func(t *testing.T, dir string)
+		want   result
+	}{
+		{
+			name: "ok",
+			want: result{checksum: statusOK, signature: statusOK, provenance: statusOK, sbom: statusOK},
+		},
+		{
+			name:   "bad checksum",
+			modify: func(t *testing.T, dir string) { writeFile(t, dir, testArtifact, "tampered") },
+			want:   result{checksum: statusFailed, signature: statusOK, provenance: statusOK, sbom: statusFailed},
+		},
+		{
+			name:   "missing checksum",
+			modify: func(t *testing.T, dir string) { os.Remove(filepath.Join(dir, testArtifact+checksumSuffix)) },
+			want:   result{checksum: statusMissing, signature: statusOK, provenance: statusOK, sbom: statusOK},
+		},
+		{
+			name:   "missing signature",
+			modify: func(t *testing.T, dir string) { os.Remove(filepath.Join(dir, testArtifact+signatureSuffix)) },
+			want:   result{checksum: statusOK, signature: statusMissing, provenance: statusOK, sbom: statusOK},
+		},
+		{
+			name:   "invalid signature",
+			modify: func(t *testing.T, dir string) { writeFile(t, dir, testArtifact+signatureSuffix, "forged") },
+			want:   result{checksum: statusOK, signature: statusFailed, provenance: statusOK, sbom: statusOK},
+		},
+		{
+			name:   "invalid provenance",
+			modify: func(t *testing.T, dir string) { writeFile(t, dir, testArtifact+provenanceSuffix, "forged") },
+			want:   result{checksum: statusOK, signature: statusOK, provenance: statusFailed, sbom: statusOK},
+		},
+		{
+			name: "without provenance nor SBOM",
+			modify: func(t *testing.T, dir string) {
+				os.Remove(filepath.Join(dir, testArtifact+provenanceSuffix))
+				os.Remove(filepath.Join(dir, testArtifact+sbomSuffix))
+			},
+			want: result{checksum: statusOK, signature: statusOK, provenance: statusNone, sbom: statusNone},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCosign(t)
+			dir := t.TempDir()
+			writeRelease(t, dir)
+			if tt.modify != nil {
+				tt.modify(t, dir)
+			}
+
+			got := verify(dir, testArtifact)
+			if got.checksum != tt.want.checksum || got.signature != tt.want.signature ||
+				got.provenance != tt.want.provenance || got.sbom != tt.want.sbom {
+				t.Errorf("got %s %s %s %s, want %s %s %s %s", got.checksum, got.signature, got.provenance, got.sbom,
+					tt.want.checksum, tt.want.signature, tt.want.provenance, tt.want.sbom)
+			}
+			if wantErrors := got.failed() && !strings.Contains(tt.name, "missing"); wantErrors != (len(got.errors) > 0) {
+				t.Errorf("got errors %q", got.errors)
+			}
+		})
+	}
+}
+
+func TestVerifyReleaseFile(t *testing.T) {
+	calls := fakeCosign(t)
+	dir := t.TempDir()
+	writeRelease(t, dir)
+
+	for _, name := range []string{"otelcol_0.150.0_components.cdx.json", "otelcol_0.150.0_inventory.json"} {
+		r := verify(dir, name)
+		if r.failed() || r.checksum != statusNone || r.signature != statusNone {
+			t.Errorf("%s: got %s %s, want unchecked", name, r.checksum, r.signature)
+		}
+	}
+	if len(*calls) != 0 {
+		t.Errorf("cosign called for the release files: %v", *calls)
+	}
+}
+
+func TestRun(t *testing.T) {
+	fakeCosign(t)
+	dir := t.TempDir()
+	writeRelease(t, dir)
+
+	var stdout, stderr bytes.Buffer
+	if err := run(dir, &stdout, &stderr); err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), testArtifact+sbomSuffix) || !strings.Contains(stdout.String(), "_inventory.json") {
+		t.Errorf("report misses artifacts:\n%s", stdout.String())
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	t.Run("empty directory", func(t *testing.T) {
+		fakeCosign(t)
+		if err := run(t.TempDir(), io.Discard, io.Discard); err == nil || errors.Is(err, errFailed) {
+			t.Errorf("got %v, want no artifacts error", err)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		fakeCosign(t)
+		if err := run(filepath.Join(t.TempDir(), "missing"), io.Discard, io.Discard); !os.IsNotExist(err) {
+			t.Errorf("got %v, want not exist", err)
+		}
+	})
+
+	t.Run("forged signature", func(t *testing.T) {
+		fakeCosign(t)
+		dir := t.TempDir()
+		writeRelease(t, dir)
+		writeFile(t, dir, testArtifact+sbomSuffix+signatureSuffix, "forged")
+
+		var stdout, stderr bytes.Buffer
+		if err := run(dir, &stdout, &stderr); !errors.Is(err, errFailed) {
+			t.Fatalf("got %v, want %v", err, errFailed)
+		}
+		if want := testArtifact + sbomSuffix + ": signature: invalid signature"; !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr %q does not contain %q", stderr.String(), want)
+		}
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		fakeCosign(t)
+		dir := t.TempDir()
+		writeRelease(t, dir)
+		os.Remove(filepath.Join(dir, testArtifact+signatureSuffix))
+
+		if err := run(dir, io.Discard, io.Discard); !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+		*allowMissingFlag = true
+		defer func() { *allowMissingFlag = false }()
+		if err := run(dir, io.Discard, io.Discard); err != nil {
+			t.Errorf("with -allow-missing: got %v", err)
+		}
+	})
+}
+
+func TestListArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		testArtifact,
+		testArtifact + checksumSuffix,
+		testArtifact + signatureSuffix,
+		testArtifact + provenanceSuffix,
+		testArtifact + sbomSuffix,
+		testArtifact + sbomSuffix + checksumSuffix,
+		testArtifact + sbomSuffix + signatureSuffix,
+		testArtifact + sbomSuffix + checksumSuffix + signatureSuffix,
+		testArtifact + checksumSuffix + signatureSuffix,
+		testArtifact + keySignatureSuffix,
+		testArtifact + keyProvenanceSuffix,
+		testArtifact + gpgSignatureSuffix,
+		testArtifact + sbomSuffix + gpgSignatureSuffix,
+		"otelcol_0.150.0_components.cdx.json",
+		"otelcol_0.150.0_inventory.json",
+	} {
+		writeFile(t, dir, name, "")
+	}
+
+	got, err := listArtifacts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"otelcol_0.150.0_components.cdx.json", "otelcol_0.150.0_inventory.json", testArtifact, testArtifact + sbomSuffix}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestListArtifactsInventory checks that the files the otelcol inventory
+// lists are the artifacts, the SBOMs and the release files.
+func TestListArtifactsInventory(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "distributions", "otelcol", "inventory.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var inventory struct {
+		Platforms []struct {
+			Artifacts []struct {
+				Name         string   `json:"name"`
+				Checksum     string   `json:"checksum"`
+				SBOM         string   `json:"sbom"`
+				SBOMChecksum string   `json:"sbom_checksum"`
+				Signatures   []string `json:"signatures"`
+			} `json:"artifacts"`
+		} `json:"platforms"`
+		Files []string `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(strings.ReplaceAll(string(data), "{{ .Version }}", "0.150.0")), &inventory); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	want := inventory.Files
+	for _, name := range inventory.Files {
+		writeFile(t, dir, name, "")
+	}
+	for _, p := range inventory.Platforms {
+		for _, a := range p.Artifacts {
+			want = append(want, a.Name)
+			if a.SBOM != "" {
+				want = append(want, a.SBOM)
+			}
+			for _, name := range append([]string{a.Name, a.Checksum, a.SBOM, a.SBOMChecksum}, a.Signatures...) {
+				if name != "" {
+					writeFile(t, dir, name, "")
+				}
+			}
+		}
+	}
+	slices.Sort(want)
+
+	got, err := listArtifacts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// fakeGPG accepts the signatures holding "valid" and records the calls.
+func fakeGPG(t *testing.T) *[]string {
+	t.Helper()
+	var calls []string
+	orig := gpg
+	t.Cleanup(func() { gpg = orig })
+	gpg = func(args ...string) error {
+		signature := args[len(args)-2]
+		calls = append(calls, signature)
+		data, err := os.ReadFile(signature)
+		if err != nil {
+			return err
+		}
+		if string(data) != "valid" {
+			return errors.New("bad signature")
+		}
+		return nil
+	}
+	return &calls
+}
+
+func TestSigningModes(t *testing.T) {
+	for _, tt := range []struct {
+		mode           string
+		signature      string
+		provenance     string
+		keyring        string
+		wantProvenance status
+	}{
+		{mode: "keyless", signature: signatureSuffix, provenance: provenanceSuffix, wantProvenance: statusOK},
+		{mode: "key", signature: keySignatureSuffix, provenance: keyProvenanceSuffix, wantProvenance: statusOK},
+		{mode: "gpg", signature: gpgSignatureSuffix, provenance: provenanceSuffix, keyring: "/keys/releases.gpg", wantProvenance: statusNone},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			cosignCalls, gpgCalls := fakeCosign(t), fakeGPG(t)
+			signature, provenance := signatureSuffix, provenanceSuffix
+			signatureSuffix, provenanceSuffix = tt.signature, tt.provenance
+			*gpgKeyringFlag = tt.keyring
+			t.Cleanup(func() {
+				signatureSuffix, provenanceSuffix = signature, provenance
+				*gpgKeyringFlag = ""
+			})
+
+			dir := t.TempDir()
+			writeRelease(t, dir)
+			if tt.wantProvenance == statusNone {
+				os.Remove(filepath.Join(dir, testArtifact+tt.provenance))
+			}
+
+			var stdout, stderr bytes.Buffer
+			if err := run(dir, &stdout, &stderr); err != nil {
+				t.Fatalf("run: %v\n%s", err, stderr.String())
+			}
+			if strings.Contains(stdout.String(), tt.signature) {
+				t.Errorf("signatures reported as artifacts:\n%s", stdout.String())
+			}
+			if r := verify(dir, testArtifact); r.signature != statusOK || r.provenance != tt.wantProvenance {
+				t.Errorf("got signature %s, provenance %s, want ok, %s", r.signature, r.provenance, tt.wantProvenance)
+			}
+
+			// Signatures are checked by gpg in gpg mode and by cosign otherwise.
+			usedGPG := slices.Contains(*gpgCalls, filepath.Join(dir, testArtifact+tt.signature))
+			if wantGPG := tt.mode == "gpg"; usedGPG != wantGPG || (wantGPG && slices.Contains(*cosignCalls, "verify-blob")) {
+				t.Errorf("gpg calls %q, cosign calls %q in %s mode", *gpgCalls, *cosignCalls, tt.mode)
+			}
+
+			writeFile(t, dir, testArtifact+tt.signature, "forged")
+			if r := verify(dir, testArtifact); r.signature != statusFailed {
+				t.Errorf("forged signature: got %s, want %s", r.signature, statusFailed)
+			}
+		})
+	}
+}
+
+// TestVerifyGPGOffline signs a file with a throwaway gpg key and verifies the
+// signature against the exported public keyring.
+func TestVerifyGPGOffline(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not found")
+	}
+
+	dir := t.TempDir()
+	home := filepath.Join(dir, "gnupg")
+	if err := os.Mkdir(home, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GNUPGHOME", home)
+	gpgRun := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("gpg", append([]string{"--batch", "--pinentry-mode=loopback", "--passphrase="}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("gpg %s: %v\n%s", args[0], err, out)
+		}
+	}
+	gpgRun("--quick-generate-key", "releases@example.com", "ed25519", "sign", "never")
+
+	artifact := writeFile(t, dir, testArtifact, "archive")
+	gpgRun("--output="+artifact+gpgSignatureSuffix, "--detach-sign", "--armor", artifact)
+	keyring := filepath.Join(dir, "releases.gpg")
+	gpgRun("--output="+keyring, "--export", "releases@example.com")
+
+	*gpgKeyringFlag = keyring
+	t.Cleanup(func() { *gpgKeyringFlag = "" })
+	if err := verifySignature(artifact, artifact+gpgSignatureSuffix); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, testArtifact, "tampered")
+	if err := verifySignature(artifact, artifact+gpgSignatureSuffix); err == nil {
+		t.Error("verified a tampered artifact")
+	}
+}
@@ -0,0 +1,20 @@
+#!/bin/sh
+
+# Copyright The OpenTelemetry Authors
+# SPDX-License-Identifier: Apache-2.0
+
+# Register the System Resource Controller subsystem, the AIX equivalent of the systemd unit.
+if ! lssrc -s otelcol-contrib >/dev/null 2>&1; then
+    mkssys -s otelcol-contrib -p /opt/freeware/bin/otelcol-contrib -a "--config=/etc/otelcol-contrib/config.yaml" \
+        -u "$(id -u otelcol-contrib)" -S -n 15 -f 9 -R -Q \
+        -o /var/log/otelcol-contrib.log -e /var/log/otelcol-contrib.log
+fi
+lsitab otelcol-contrib >/dev/null 2>&1 || mkitab "otelcol-contrib:2:once:/usr/bin/startsrc -s otelcol-contrib >/dev/console 2>&1"
+
+if [ -f /etc/otelcol-contrib/config.yaml ]; then
+    if lssrc -s otelcol-contrib | grep -qw active; then
+        stopsrc -s otelcol-contrib
+        while lssrc -s otelcol-contrib | grep -qw active; do sleep 1; done
+    fi
+    startsrc -s otelcol-contrib
+fi
@@ -0,0 +1,7 @@
+#!/bin/sh
+
+# Copyright The OpenTelemetry Authors
+# SPDX-License-Identifier: Apache-2.0
+
+lsgroup otelcol-contrib >/dev/null 2>&1 || mkgroup otelcol-contrib
+lsuser otelcol-contrib >/dev/null 2>&1 || mkuser pgrp=otelcol-contrib groups=otelcol-contrib home=/var/lib/otelcol-contrib shell=/usr/bin/false login=false rlogin=false otelcol-contrib
@@ -0,0 +1,10 @@
+#!/bin/sh
+
+# Copyright The OpenTelemetry Authors
+# SPDX-License-Identifier: Apache-2.0
+
+if [ "$1" != "1" ]; then
+    stopsrc -s otelcol-contrib >/dev/null 2>&1 || true
+    rmitab otelcol-contrib >/dev/null 2>&1 || true
+    rmssys -s otelcol-contrib >/dev/null 2>&1 || true
+fi
@@ -0,0 +1,20 @@
+#!/bin/sh
+
+# Copyright The OpenTelemetry Authors
+# SPDX-License-Identifier: Apache-2.0
+
+# Register the System Resource Controller subsystem, the AIX equivalent of the systemd unit.
+if ! lssrc -s otelcol-otlp >/dev/null 2>&1; then
+    mkssys -s otelcol-otlp -p /opt/freeware/bin/otelcol-otlp -a "--config=/etc/otelcol-otlp/config.yaml" \
+        -u "$(id -u otelcol-otlp)" -S -n 15 -f 9 -R -Q \
+        -o /var/log/otelcol-otlp.log -e /var/log/otelcol-otlp.log
+fi
+lsitab otelcol-otlp >/dev/null 2>&1 || mkitab "otelcol-otlp:2:once:/usr/bin/startsrc -s otelcol-otlp >/dev/console 2>&1"
+
+if [ -f /etc/otelcol-otlp/config.yaml ]; then
+    if lssrc -s otelcol-otlp | grep -qw active; then
+        stopsrc -s otelcol-otlp
+        while lssrc -s otelcol-otlp | grep -qw active; do sleep 1; done
+    fi
+    startsrc -s otelcol-otlp
+fi
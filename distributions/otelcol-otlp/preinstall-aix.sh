@@ -0,0 +1,7 @@
+#!/bin/sh
+
+# Copyright The OpenTelemetry Authors
+# SPDX-License-Identifier: Apache-2.0
+
+lsgroup otelcol-otlp >/dev/null 2>&1 || mkgroup otelcol-otlp
+lsuser otelcol-otlp >/dev/null 2>&1 || mkuser pgrp=otelcol-otlp groups=otelcol-otlp home=/var/lib/otelcol-otlp shell=/usr/bin/false login=false rlogin=false otelcol-otlp
@@ -0,0 +1,20 @@
+#!/bin/sh
+
+# Copyright The OpenTelemetry Authors
+# SPDX-License-Identifier: Apache-2.0
+
+# Register the System Resource Controller subsystem, the AIX equivalent of the systemd unit.
+if ! lssrc -s otelcol >/dev/null 2>&1; then
+    mkssys -s otelcol -p /opt/freeware/bin/otelcol -a "--config=/etc/otelcol/config.yaml" \
+        -u "$(id -u otel)" -S -n 15 -f 9 -R -Q \
+        -o /var/log/otelcol.log -e /var/log/otelcol.log
+fi
+lsitab otelcol >/dev/null 2>&1 || mkitab "otelcol:2:once:/usr/bin/startsrc -s otelcol >/dev/console 2>&1"
+
+if [ -f /etc/otelcol/config.yaml ]; then
+    if lssrc -s otelcol | grep -qw active; then
+        stopsrc -s otelcol
+        while lssrc -s otelcol | grep -qw active; do sleep 1; done
+    fi
+    startsrc -s otelcol
+fi
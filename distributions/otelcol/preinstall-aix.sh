@@ -0,0 +1,7 @@
+#!/bin/sh
+
+# Copyright The OpenTelemetry Authors
+# SPDX-License-Identifier: Apache-2.0
+
+lsgroup otel >/dev/null 2>&1 || mkgroup otel
+lsuser otel >/dev/null 2>&1 || mkuser pgrp=otel groups=otel home=/var/lib/otelcol shell=/usr/bin/false login=false rlogin=false otel
@@ -0,0 +1,10 @@
+#!/bin/sh
+
+# Copyright The OpenTelemetry Authors
+# SPDX-License-Identifier: Apache-2.0
+
+if [ "$1" != "1" ]; then
+    stopsrc -s otelcol >/dev/null 2>&1 || true
+    rmitab otelcol >/dev/null 2>&1 || true
+    rmssys -s otelcol >/dev/null 2>&1 || true
+fi
@@ -0,0 +1,23 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cosign holds the cosign signing options shared by the tools
+// attesting release artifacts and images.
+//
+// cosign signs keyless by default. With COSIGN_KEY set, it signs with that key
+// and skips the transparency log, so that attestations can be verified offline.
+package cosign
+
+import "os"
+
+// KeyEnv names the environment variable holding the cosign signing key.
+const KeyEnv = "COSIGN_KEY"
+
+// KeyArgs returns the cosign arguments to sign with COSIGN_KEY, if set.
+func KeyArgs() []string {
+	key := os.Getenv(KeyEnv)
+	if key == "" {
+		return nil
+	}
+	return []string{"--key=" + key, "--tlog-upload=false"}
+}
@@ -0,0 +1,21 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cosign
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKeyArgs(t *testing.T) {
+	t.Setenv(KeyEnv, "")
+	if args := KeyArgs(); args != nil {
+		t.Errorf("keyless args = %q, want none", args)
+	}
+
+	t.Setenv(KeyEnv, "/tmp/cosign.key")
+	if args, want := KeyArgs(), []string{"--key=/tmp/cosign.key", "--tlog-upload=false"}; !slices.Equal(args, want) {
+		t.Errorf("key args = %q, want %q", args, want)
+	}
+}
@@ -32,7 +32,22 @@ do
 
     if [[ "$distribution" == "otelcol-contrib" ]]; then
         ${GO} run cmd/goreleaser/main.go -d "${distribution}" --generate-build-step > "${target_path}/${distribution}/.goreleaser-build.yaml"
+        for channel in rc beta; do
+            ${GO} run cmd/goreleaser/main.go -d "${distribution}" --generate-build-step -channel "${channel}" > "${target_path}/${distribution}/.goreleaser-build-${channel}.yaml"
+        done
+    fi
+
+    if [[ "$distribution" == "otelcol" || "$distribution" == "otelcol-contrib" || "$distribution" == "otelcol-k8s" ]]; then
+        ${GO} run cmd/goreleaser/main.go -d "${distribution}" --fips > "${target_path}/${distribution}/.goreleaser-fips.yaml"
+        ${GO} run cmd/goreleaser/main.go -d "${distribution}" --fips --inventory > "${target_path}/${distribution}/inventory-fips.json"
+    fi
+
+    if [[ "$target_path" == "./distributions" ]]; then
+        for channel in rc beta; do
+            ${GO} run cmd/goreleaser/main.go -d "${distribution}" -channel "${channel}" > "${target_path}/${distribution}/.goreleaser-${channel}.yaml"
+        done
     fi
 
     ${GO} run cmd/goreleaser/main.go -d "${distribution}" > "${target_path}/${distribution}/.goreleaser.yaml"
+    ${GO} run cmd/goreleaser/main.go -d "${distribution}" --inventory > "${target_path}/${distribution}/inventory.json"
 done
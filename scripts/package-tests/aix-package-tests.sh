@@ -0,0 +1,54 @@
+#!/bin/bash
+
+# Copyright The OpenTelemetry Authors
+# SPDX-License-Identifier: Apache-2.0
+
+# Inspects an AIX rpm on Linux, as the package cannot be installed here.
+
+set -euo pipefail
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )"
+# shellcheck source=scripts/package-tests/common.sh
+source "$SCRIPT_DIR"/common.sh
+
+PKG_PATH="${1:-}"
+DISTRO="${2:-}"
+
+if [[ -z "$PKG_PATH" || -z "$DISTRO" ]]; then
+    echo "usage: ${BASH_SOURCE[0]} AIX_RPM_PATH DISTRO" >&2
+    exit 1
+fi
+
+if [[ ! -f "$PKG_PATH" ]]; then
+    echo "$PKG_PATH not found!" >&2
+    exit 1
+fi
+
+fail() {
+    echo "$1" >&2
+    exit 1
+}
+
+echo "Checking $PKG_PATH target OS ..."
+pkg_os="$( rpm -qp --queryformat '%{OS}' "$PKG_PATH" )"
+[[ "$pkg_os" == "aix" ]] || fail "expected package OS aix, got $pkg_os"
+
+echo "Checking $PKG_PATH contents ..."
+contents="$( rpm -qpl "$PKG_PATH" )"
+echo "$contents"
+grep -qx "/opt/freeware/bin/$DISTRO" <<< "$contents" || fail "/opt/freeware/bin/$DISTRO missing from package"
+if [[ -f "distributions/$DISTRO/config.yaml" ]]; then
+    grep -qx "/etc/$DISTRO/config.yaml" <<< "$contents" || fail "/etc/$DISTRO/config.yaml missing from package"
+    rpm -qpc "$PKG_PATH" | grep -qx "/etc/$DISTRO/config.yaml" || fail "/etc/$DISTRO/config.yaml not marked as config"
+fi
+
+echo "Checking $PKG_PATH SRC registration scripts ..."
+scripts="$( rpm -qp --scripts "$PKG_PATH" )"
+echo "$scripts"
+grep -q "mkssys -s $DISTRO " <<< "$scripts" || fail "postinstall does not register the $DISTRO SRC subsystem"
+grep -q "mkitab \"$DISTRO:" <<< "$scripts" || fail "postinstall does not add the $DISTRO inittab entry"
+grep -q "rmssys -s $DISTRO" <<< "$scripts" || fail "preremove does not remove the $DISTRO SRC subsystem"
+
+check_pkg_signature "$PKG_PATH"
+
+echo "$PKG_PATH looks good"
@@ -42,3 +42,106 @@ uninstall_pkg() {
         podman exec "$container" rpm -e "$pkg_name"
     fi
 }
+
+# Generates a throwaway GPG key to build signed test packages with: the armored
+# private key is written to $1, protected by the passphrase $3, and the public
+# key to $2. Runs on the host, gpg must be installed.
+generate_signing_key() {
+    local private_key="$1"
+    local public_key="$2"
+    local passphrase="$3"
+    local gnupghome
+    gnupghome="$( mktemp -d )"
+
+    echo "Generating a throwaway package signing key ..."
+    GNUPGHOME="$gnupghome" gpg --batch --pinentry-mode loopback --passphrase "$passphrase" \
+        --quick-generate-key "OpenTelemetry Collector package tests <package-tests@opentelemetry.io>" rsa4096 sign 1d
+    GNUPGHOME="$gnupghome" gpg --batch --pinentry-mode loopback --passphrase "$passphrase" \
+        --armor --export-secret-keys > "$private_key"
+    GNUPGHOME="$gnupghome" gpg --batch --armor --export > "$public_key"
+    rm -rf "$gnupghome"
+}
+
+# Checks that a package carries an embedded signature when the packages were
+# built with NFPM_SIGNING_KEY_FILE, or when PACKAGE_SIGNING_PUBLIC_KEY names the
+# public key they were signed with: an rpm header signature or a deb
+# _gpgorigin member. With PACKAGE_SIGNING_PUBLIC_KEY set, the signature is also
+# verified. Runs on the host, rpm, ar and gpg must be installed.
+check_pkg_signature() {
+    local pkg_path="$1"
+    local public_key="${PACKAGE_SIGNING_PUBLIC_KEY:-}"
+
+    if [[ -z "${NFPM_SIGNING_KEY_FILE:-}" && -z "$public_key" ]]; then
+        echo "NFPM_SIGNING_KEY_FILE and PACKAGE_SIGNING_PUBLIC_KEY not set, skipping $pkg_path signature check"
+        return 0
+    fi
+
+    echo "Checking $pkg_path signature ..."
+    if [[ "${pkg_path##*.}" = "deb" ]]; then
+        if ! ar t "$pkg_path" | grep -qx "_gpgorigin"; then
+            echo "$pkg_path is not signed" >&2
+            return 1
+        fi
+        if [[ -n "$public_key" ]]; then
+            verify_deb_signature "$pkg_path" "$public_key" || return 1
+        fi
+    else
+        # RSA keys sign the RSAHEADER tag, DSA and EdDSA keys the DSAHEADER
+        # tag, older packages the SIGPGP and SIGGPG tags and rpm 6 the OPENPGP
+        # tag, unknown to older rpm versions.
+        local tag signature=""
+        for tag in RSAHEADER DSAHEADER SIGPGP SIGGPG OPENPGP; do
+            signature="$( rpm -qp --queryformat "%{$tag:pgpsig}" "$pkg_path" 2> /dev/null || true )"
+            if [[ -n "$signature" && "$signature" != "(none)" ]]; then
+                break
+            fi
+            signature=""
+        done
+        if [[ -z "$signature" ]]; then
+            echo "$pkg_path is not signed" >&2
+            return 1
+        fi
+        echo "$signature"
+        if [[ -n "$public_key" ]]; then
+            verify_rpm_signature "$pkg_path" "$public_key" || return 1
+        fi
+    fi
+    echo "$pkg_path is signed"
+}
+
+# Verifies the embedded rpm signature with a public key, in a throwaway rpm
+# database.
+verify_rpm_signature() {
+    local pkg_path="$1"
+    local public_key="$2"
+    local dbpath
+    dbpath="$( mktemp -d )"
+
+    rpmkeys --dbpath "$dbpath" --import "$public_key"
+    if ! rpmkeys --dbpath "$dbpath" --checksig "$pkg_path"; then
+        rm -rf "$dbpath"
+        echo "$pkg_path signature does not verify" >&2
+        return 1
+    fi
+    rm -rf "$dbpath"
+}
+
+# Verifies the _gpgorigin signature of a deb, made over the concatenated
+# debian-binary, control and data members, with a public key.
+verify_deb_signature() {
+    local pkg_path="$1"
+    local public_key="$2"
+    local workdir
+    workdir="$( mktemp -d )"
+
+    ar p "$pkg_path" _gpgorigin > "$workdir"/_gpgorigin
+    local members=()
+    mapfile -t members < <( ar t "$pkg_path" | grep -v '^_gpgorigin$' )
+    GNUPGHOME="$workdir" gpg --batch --import "$public_key"
+    if ! ar p "$pkg_path" "${members[@]}" | GNUPGHOME="$workdir" gpg --batch --verify "$workdir"/_gpgorigin -; then
+        rm -rf "$workdir"
+        echo "$pkg_path signature does not verify" >&2
+        return 1
+    fi
+    rm -rf "$workdir"
+}
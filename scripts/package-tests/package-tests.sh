@@ -79,6 +79,8 @@ podman run --name "$container_name" --privileged -v /sys/fs/cgroup:/sys/fs/cgrou
 # TODO(MovieStoreGuy): re-enable when we have a way validate that systemd is fully running
 # $container_exec systemctl is-system-running --wait --quiet
 
+check_pkg_signature "$PKG_PATH"
+
 install_pkg "$container_name" "$PKG_PATH"
 
 if [[ "$pkg_type" == "rpm" ]]; then
@@ -48,39 +48,50 @@ func TestMSI(t *testing.T) {
 	}
 }
 
-func runMsiTest(t *testing.T, test msiTest, msiInstallerPath string) {
-	// Build the MSI installation arguments and include the MSI properties map.
-	installLogFile := filepath.Join(os.TempDir(), "install.log")
-	args := []string{"/i", msiInstallerPath, "/qn", "/l*v", installLogFile}
-
-	serviceArgs := quotedIfRequired(test.collectorServiceArgs)
-	if test.collectorServiceArgs != "" {
-		args = append(args, "COLLECTOR_SVC_ARGS="+serviceArgs)
+func TestMSIUpgradePreservesConfig(t *testing.T) {
+	previousInstallerPath := os.Getenv("MSI_TEST_PREVIOUS_COLLECTOR_PATH")
+	if previousInstallerPath == "" {
+		t.Skip("MSI_TEST_PREVIOUS_COLLECTOR_PATH environment variable is not set")
 	}
+	_, err := os.Stat(previousInstallerPath)
+	require.NoError(t, err)
+	msiInstallerPath := getInstallerPath(t)
 
-	// Run the MSI installer
-	installCmd := exec.Command("msiexec")
-
-	// msiexec is one of the noticeable exceptions about how to format the parameters,
-	// see https://pkg.go.dev/os/exec#Command, so we need to join the args manually.
-	cmdLine := strings.Join(args, " ")
-	installCmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: "msiexec " + cmdLine}
-	err := installCmd.Run()
-	if err != nil {
-		logText, _ := os.ReadFile(installLogFile)
-		t.Log(string(logText))
-	}
-	t.Logf("Install command: %s", installCmd.SysProcAttr.CmdLine)
-	require.NoError(t, err, "Failed to install the MSI: %v\nArgs: %v", err, args)
+	// config.yaml is permanent, remove the copy left by earlier installs so that
+	// the previous MSI has to install it.
+	configFile := filepath.Join(getCollectorDir(t), "config.yaml")
+	require.NoError(t, removeIfExists(configFile))
+	t.Cleanup(func() { os.Remove(configFile) })
+
+	installMsi(t, previousInstallerPath, "")
+	// The upgrade removes the previous version, unless it failed before.
+	t.Cleanup(func() {
+		if t.Failed() {
+			cleanupMsi(t, previousInstallerPath)
+		}
+	})
+	original, err := os.ReadFile(configFile)
+	require.NoError(t, err, "config.yaml not installed by the previous MSI")
+	require.Contains(t, string(original), "service:", "config.yaml installed by the previous MSI is not a collector configuration")
+
+	// Simulate an operator editing the configuration shipped by the previous version.
+	edited := append(original, []byte("\n# edited by the MSI upgrade test\n")...)
+	require.NoError(t, os.WriteFile(configFile, edited, 0o644))
+
+	installMsi(t, msiInstallerPath, "")
+	t.Cleanup(func() { cleanupMsi(t, msiInstallerPath) })
+
+	actual, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(edited), string(actual), "config.yaml was modified by the MSI upgrade")
+	_, err = os.Stat(configFile + ".upgrade")
+	assert.True(t, os.IsNotExist(err), "config.yaml.upgrade left behind by the MSI upgrade")
+}
 
-	defer func() {
-		// Uninstall the MSI
-		uninstallCmd := exec.Command("msiexec")
-		uninstallCmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: "msiexec /x " + msiInstallerPath + " /qn"}
-		err := uninstallCmd.Run()
-		t.Logf("Uninstall command: %s", uninstallCmd.SysProcAttr.CmdLine)
-		require.NoError(t, err, "Failed to uninstall the MSI: %v", err)
-	}()
+func runMsiTest(t *testing.T, test msiTest, msiInstallerPath string) {
+	serviceArgs := quotedIfRequired(test.collectorServiceArgs)
+	installMsi(t, msiInstallerPath, test.collectorServiceArgs)
+	defer uninstallMsi(t, msiInstallerPath)
 
 	// Verify the service
 	scm, err := mgr.Connect()
@@ -115,6 +126,56 @@ func runMsiTest(t *testing.T, test msiTest, msiInstallerPath string) {
 	assertServiceCommand(t, collectorSvcName, serviceArgs)
 }
 
+func installMsi(t *testing.T, msiInstallerPath, collectorServiceArgs string) {
+	// Build the MSI installation arguments and include the MSI properties map.
+	installLogFile := filepath.Join(os.TempDir(), "install.log")
+	args := []string{"/i", msiInstallerPath, "/qn", "/l*v", installLogFile}
+
+	if collectorServiceArgs != "" {
+		args = append(args, "COLLECTOR_SVC_ARGS="+quotedIfRequired(collectorServiceArgs))
+	}
+
+	// Run the MSI installer
+	installCmd := exec.Command("msiexec")
+
+	// msiexec is one of the noticeable exceptions about how to format the parameters,
+	// see https://pkg.go.dev/os/exec#Command, so we need to join the args manually.
+	cmdLine := strings.Join(args, " ")
+	installCmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: "msiexec " + cmdLine}
+	err := installCmd.Run()
+	if err != nil {
+		logText, _ := os.ReadFile(installLogFile)
+		t.Log(string(logText))
+	}
+	t.Logf("Install command: %s", installCmd.SysProcAttr.CmdLine)
+	require.NoError(t, err, "Failed to install the MSI: %v\nArgs: %v", err, args)
+}
+
+func uninstallMsi(t *testing.T, msiInstallerPath string) {
+	uninstallCmd := exec.Command("msiexec")
+	uninstallCmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: "msiexec /x " + msiInstallerPath + " /qn"}
+	err := uninstallCmd.Run()
+	t.Logf("Uninstall command: %s", uninstallCmd.SysProcAttr.CmdLine)
+	require.NoError(t, err, "Failed to uninstall the MSI: %v", err)
+}
+
+// cleanupMsi uninstalls an MSI that may not be installed anymore, without
+// failing the test.
+func cleanupMsi(t *testing.T, msiInstallerPath string) {
+	uninstallCmd := exec.Command("msiexec")
+	uninstallCmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: "msiexec /x " + msiInstallerPath + " /qn"}
+	if err := uninstallCmd.Run(); err != nil {
+		t.Logf("Failed to uninstall %s: %v", msiInstallerPath, err)
+	}
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func assertServiceCommand(t *testing.T, serviceName, collectorServiceArgs string) {
 	// Verify the service command
 	actualCommand := getServiceCommand(t, serviceName)
@@ -138,10 +199,7 @@ func getServiceCommand(t *testing.T, serviceName string) string {
 }
 
 func expectedServiceCommand(t *testing.T, serviceName, collectorServiceArgs string) string {
-	programFilesDir := os.Getenv("PROGRAMFILES")
-	require.NotEmpty(t, programFilesDir, "PROGRAMFILES environment variable is not set")
-
-	collectorDir := filepath.Join(programFilesDir, "OpenTelemetry Collector")
+	collectorDir := getCollectorDir(t)
 	collectorExe := filepath.Join(collectorDir, serviceName) + ".exe"
 
 	if collectorServiceArgs == "" {
@@ -155,6 +213,12 @@ func expectedServiceCommand(t *testing.T, serviceName, collectorServiceArgs stri
 	return quotedIfRequired(collectorExe) + " " + collectorServiceArgs
 }
 
+func getCollectorDir(t *testing.T) string {
+	programFilesDir := os.Getenv("PROGRAMFILES")
+	require.NotEmpty(t, programFilesDir, "PROGRAMFILES environment variable is not set")
+	return filepath.Join(programFilesDir, "OpenTelemetry Collector")
+}
+
 func getServiceName(t *testing.T) string {
 	serviceName := os.Getenv("MSI_TEST_COLLECTOR_SERVICE_NAME")
 	require.NotEmpty(t, serviceName, "MSI_TEST_COLLECTOR_SERVICE_NAME environment variable is not set")